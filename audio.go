@@ -0,0 +1,187 @@
+package gpt3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+)
+
+// AudioService handles communication with the audio related methods of the
+// OpenAI API.
+type AudioService struct {
+	client *Client
+}
+
+// TranscriptionRequest holds the optional fields of a transcription
+// request. Fields left empty are not sent.
+type TranscriptionRequest struct {
+	Language       string
+	Prompt         string
+	ResponseFormat string
+	Temperature    *float64
+}
+
+// A TranscriptionOption sets a field of a TranscriptionRequest.
+type TranscriptionOption func(*TranscriptionRequest)
+
+// TranscriptionLanguage sets the ISO-639-1 language of the audio, such as
+// "en", which improves accuracy and latency.
+func TranscriptionLanguage(language string) TranscriptionOption {
+	return func(r *TranscriptionRequest) { r.Language = language }
+}
+
+// TranscriptionPrompt sets text that guides the model's style or continues
+// a previous audio segment.
+func TranscriptionPrompt(prompt string) TranscriptionOption {
+	return func(r *TranscriptionRequest) { r.Prompt = prompt }
+}
+
+// TranscriptionResponseFormat sets the format of the transcript: "json"
+// (the default), "text", "srt", "verbose_json" or "vtt".
+func TranscriptionResponseFormat(format string) TranscriptionOption {
+	return func(r *TranscriptionRequest) { r.ResponseFormat = format }
+}
+
+// TranscriptionTemperature sets the sampling temperature, between 0 and 1.
+func TranscriptionTemperature(t float64) TranscriptionOption {
+	return func(r *TranscriptionRequest) { r.Temperature = Float64(t) }
+}
+
+// Transcription is the result of transcribing audio.
+type Transcription struct {
+	// Text is the transcript. For the text, srt and vtt response formats
+	// it holds the raw response body.
+	Text string `json:"text"`
+
+	Language string  `json:"language,omitempty"`
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// Transcribe converts speech in audio to text with model, such as
+// "whisper-1". filename is sent with the upload and its extension tells
+// the API the audio format.
+func (s *AudioService) Transcribe(ctx context.Context, audio io.Reader, filename, model string, options ...TranscriptionOption) (*Transcription, error) {
+	r := new(TranscriptionRequest)
+	for _, option := range options {
+		option(r)
+	}
+
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return nil, err
+	}
+	fields := []struct{ name, value string }{
+		{"model", model},
+		{"language", r.Language},
+		{"prompt", r.Prompt},
+		{"response_format", r.ResponseFormat},
+	}
+	if r.Temperature != nil {
+		fields = append(fields, struct{ name, value string }{"temperature", strconv.FormatFloat(*r.Temperature, 'f', -1, 64)})
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if err := w.WriteField(f.name, f.value); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewUploadRequest("POST", "audio/transcriptions", body, w.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+
+	t := new(Transcription)
+	switch r.ResponseFormat {
+	case "", "json", "verbose_json":
+		_, err = s.client.Do(ctx, req, t)
+	default:
+		var text bytes.Buffer
+		_, err = s.client.Do(ctx, req, &text)
+		t.Text = text.String()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SpeechRequest represents the JSON body sent to the speech endpoint.
+// Fields left unset are omitted and take the API defaults.
+type SpeechRequest struct {
+	Model          string   `json:"model"`
+	Input          string   `json:"input"`
+	Voice          string   `json:"voice"`
+	ResponseFormat string   `json:"response_format,omitempty"`
+	Speed          *float64 `json:"speed,omitempty"`
+}
+
+// A SpeechOption sets a field of a SpeechRequest.
+type SpeechOption func(*SpeechRequest)
+
+// SpeechResponseFormat sets the audio format: "mp3" (the default), "opus",
+// "aac", "flac", "wav" or "pcm".
+func SpeechResponseFormat(format string) SpeechOption {
+	return func(r *SpeechRequest) { r.ResponseFormat = format }
+}
+
+// SpeechSpeed sets the speed of the generated audio, between 0.25 and 4.
+func SpeechSpeed(speed float64) SpeechOption {
+	return func(r *SpeechRequest) { r.Speed = Float64(speed) }
+}
+
+// speechVoices are the voices accepted by the speech endpoint.
+var speechVoices = map[string]bool{
+	"alloy":   true,
+	"ash":     true,
+	"ballad":  true,
+	"coral":   true,
+	"echo":    true,
+	"fable":   true,
+	"nova":    true,
+	"onyx":    true,
+	"sage":    true,
+	"shimmer": true,
+	"verse":   true,
+}
+
+// Speech converts input to spoken audio with model, such as "tts-1", in the
+// given voice, such as "alloy", and returns the encoded audio.
+func (s *AudioService) Speech(ctx context.Context, input, model, voice string, options ...SpeechOption) ([]byte, error) {
+	var audio bytes.Buffer
+	if err := s.SpeechTo(ctx, &audio, input, model, voice, options...); err != nil {
+		return nil, err
+	}
+	return audio.Bytes(), nil
+}
+
+// SpeechTo is like Speech but writes the audio to w as it is received.
+func (s *AudioService) SpeechTo(ctx context.Context, w io.Writer, input, model, voice string, options ...SpeechOption) error {
+	if !speechVoices[voice] {
+		return fmt.Errorf("gpt3: unknown voice %q", voice)
+	}
+	r := &SpeechRequest{Model: model, Input: input, Voice: voice}
+	for _, option := range options {
+		option(r)
+	}
+
+	req, err := s.client.NewRequest("POST", "audio/speech", r)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Do(ctx, req, w)
+	return err
+}
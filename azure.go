@@ -0,0 +1,38 @@
+package gpt3
+
+import (
+	"net/url"
+	"strings"
+)
+
+// AzureConfig describes an Azure OpenAI deployment.
+type AzureConfig struct {
+	// Endpoint is the resource endpoint, e.g.
+	// "https://my-resource.openai.azure.com".
+	Endpoint string
+
+	// Deployment is the name of the model deployment to send requests to.
+	Deployment string
+
+	// APIVersion is sent as the api-version query parameter, e.g.
+	// "2024-02-01".
+	APIVersion string
+}
+
+// NewAzureClient returns a client for an Azure OpenAI deployment. Requests
+// are sent to {endpoint}/openai/deployments/{deployment}/ with the
+// api-version query parameter, and authenticate with the api-key header
+// instead of a bearer token. The services and options behave as they do for
+// a client returned by NewClient.
+func NewAzureClient(apiKey string, config AzureConfig, opts ...ClientOption) (*Client, error) {
+	endpoint := strings.TrimSuffix(config.Endpoint, "/")
+	baseURL, err := url.Parse(endpoint + "/openai/deployments/" + url.PathEscape(config.Deployment) + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewClientWithOptions(apiKey, opts...)
+	c.BaseURL = baseURL
+	c.azure = &config
+	return c, nil
+}
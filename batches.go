@@ -0,0 +1,156 @@
+package gpt3
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// BatchesService handles communication with the batch related methods of
+// the OpenAI API. A batch runs the requests of an uploaded JSON Lines file
+// asynchronously at a lower price than the same requests sent one by one.
+type BatchesService struct {
+	client *Client
+}
+
+// BatchRequest represents the JSON body sent to create a batch.
+type BatchRequest struct {
+	InputFileID      string            `json:"input_file_id"`
+	Endpoint         string            `json:"endpoint"`
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+}
+
+// Values of the status of a batch.
+const (
+	BatchValidating = "validating"
+	BatchFailed     = "failed"
+	BatchInProgress = "in_progress"
+	BatchFinalizing = "finalizing"
+	BatchCompleted  = "completed"
+	BatchExpired    = "expired"
+	BatchCancelling = "cancelling"
+	BatchCancelled  = "cancelled"
+)
+
+// Batch describes a batch of requests.
+type Batch struct {
+	ID               string             `json:"id"`
+	Object           string             `json:"object"`
+	Endpoint         string             `json:"endpoint"`
+	InputFileID      string             `json:"input_file_id"`
+	CompletionWindow string             `json:"completion_window"`
+	Status           string             `json:"status"`
+	OutputFileID     string             `json:"output_file_id"`
+	ErrorFileID      string             `json:"error_file_id"`
+	CreatedAt        int64              `json:"created_at"`
+	CompletedAt      int64              `json:"completed_at"`
+	ExpiresAt        int64              `json:"expires_at"`
+	RequestCounts    BatchRequestCounts `json:"request_counts"`
+	Metadata         map[string]string  `json:"metadata"`
+	Errors           *BatchErrors       `json:"errors"`
+}
+
+// BatchErrors lists the problems that made a batch fail validation.
+type BatchErrors struct {
+	Data []*BatchError `json:"data"`
+}
+
+// BatchError is a problem with a batch or one of its input lines.
+type BatchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+}
+
+// BatchRequestCounts reports the progress of the requests in a batch.
+type BatchRequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// Done reports whether the batch has reached a final status. The results
+// of a completed batch are in the file with ID OutputFileID, and the
+// requests that failed in the file with ID ErrorFileID.
+func (b *Batch) Done() bool {
+	switch b.Status {
+	case BatchFailed, BatchCompleted, BatchExpired, BatchCancelled:
+		return true
+	}
+	return false
+}
+
+// BatchList represents the response of the list batches endpoint.
+type BatchList struct {
+	Object  string   `json:"object"`
+	Data    []*Batch `json:"data"`
+	HasMore bool     `json:"has_more"`
+}
+
+// Create starts a batch running the requests in the uploaded file with ID
+// inputFileID against endpoint, such as "/v1/chat/completions", within
+// completionWindow, which must currently be "24h".
+func (s *BatchesService) Create(ctx context.Context, inputFileID, endpoint, completionWindow string) (*Batch, error) {
+	r := &BatchRequest{InputFileID: inputFileID, Endpoint: endpoint, CompletionWindow: completionWindow}
+	req, err := s.client.NewRequest("POST", "batches", r)
+	if err != nil {
+		return nil, err
+	}
+
+	b := new(Batch)
+	_, err = s.client.Do(ctx, req, b)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Retrieve returns the batch with the given ID.
+func (s *BatchesService) Retrieve(ctx context.Context, id string) (*Batch, error) {
+	u := fmt.Sprintf("batches/%v", url.PathEscape(id))
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b := new(Batch)
+	_, err = s.client.Do(ctx, req, b)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Cancel cancels the batch with the given ID. The batch moves to the
+// cancelling status and, some minutes later, to cancelled.
+func (s *BatchesService) Cancel(ctx context.Context, id string) (*Batch, error) {
+	u := fmt.Sprintf("batches/%v/cancel", url.PathEscape(id))
+	req, err := s.client.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b := new(Batch)
+	_, err = s.client.Do(ctx, req, b)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// List returns the first page of the organization's batches, most recent
+// first. Use ListAll to fetch them all.
+func (s *BatchesService) List(ctx context.Context) (*BatchList, error) {
+	req, err := s.client.NewRequest("GET", "batches", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(BatchList)
+	_, err = s.client.Do(ctx, req, l)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,118 @@
+package gpt3
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// A Cache stores completions keyed by a hash of the request that produced
+// them. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (*Completions, bool)
+	Set(key string, c *Completions)
+}
+
+// DefaultCachePolicy caches a request only when it sets a temperature of
+// zero, so that the cached answer is one the API would likely give again.
+func DefaultCachePolicy(r *CompletionRequest) bool {
+	return r.Temperature != nil && *r.Temperature == 0
+}
+
+// cacheKey returns the cache key for r, a hash of the JSON body sent for
+// it together with where and as whom it is sent: the base URL, the
+// organization, the API key in effect and any per-request headers. Requests
+// made with different credentials therefore never share an entry.
+func (c *Client) cacheKey(r *CompletionRequest) (string, bool) {
+	body, err := withExtra(r, r.extra)
+	if err != nil {
+		return "", false
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", false
+	}
+	apiKey := c.APIKey
+	if r.apiKey != "" {
+		apiKey = r.apiKey
+	}
+	h := sha256.New()
+	for _, s := range []string{c.BaseURL.String(), c.Organization, apiKey} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	names := make([]string, 0, len(r.header))
+	for name := range r.header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name))
+		for _, value := range r.header[name] {
+			h.Write([]byte{0})
+			h.Write([]byte(value))
+		}
+		h.Write([]byte{0})
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// LRUCache is an in-memory Cache holding a fixed number of entries and
+// evicting the least recently used one when full.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value *Completions
+}
+
+// NewLRUCache returns an LRUCache holding up to capacity entries.
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the completions stored under key, if any.
+func (l *LRUCache) Get(key string) (*Completions, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+// Set stores c under key, evicting the least recently used entry if the
+// cache is full.
+func (l *LRUCache) Set(key string, c *Completions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.capacity <= 0 {
+		return
+	}
+	if e, ok := l.items[key]; ok {
+		e.Value.(*lruEntry).value = c
+		l.order.MoveToFront(e)
+		return
+	}
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: c})
+	if l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*lruEntry).key)
+	}
+}
@@ -0,0 +1,525 @@
+package gpt3
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ChatService handles communication with the chat completion related
+// methods of the OpenAI API.
+type ChatService struct {
+	client *Client
+}
+
+// Message is a single message in a chat conversation.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+
+	// Parts, if not empty, replaces Content with a list of text and image
+	// parts, so that images can be sent to vision models.
+	Parts []ContentPart `json:"-"`
+
+	// ToolCalls holds the tools an assistant message asks to call.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// ToolCallID identifies the call a "tool" message responds to.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The content is encoded as a string,
+// or as an array when the message has Parts.
+func (m Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	if len(m.Parts) == 0 {
+		return json.Marshal(message(m))
+	}
+	return json.Marshal(struct {
+		message
+		Content []ContentPart `json:"content"`
+	}{message(m), m.Parts})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting content given as a
+// string or as an array of parts.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type message Message
+	var v struct {
+		message
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*m = Message(v.message)
+	if len(v.Content) > 0 && v.Content[0] == '[' {
+		return json.Unmarshal(v.Content, &m.Parts)
+	}
+	if len(v.Content) > 0 && string(v.Content) != "null" {
+		return json.Unmarshal(v.Content, &m.Content)
+	}
+	return nil
+}
+
+// ContentPart is a part of the content of a message: text or an image.
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL locates an image sent to the model, either on the web or inline
+// as a data URL. Detail is "low", "high" or "auto", the default.
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// TextPart returns a content part holding text.
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: "text", Text: text}
+}
+
+// ImagePart returns a content part referring to the image at url.
+func ImagePart(url string) ContentPart {
+	return ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: url}}
+}
+
+// ImageDataPart returns a content part holding the image data inline,
+// encoded as a base64 data URL. mediaType is the type of the data, such as
+// "image/png".
+func ImageDataPart(data []byte, mediaType string) ContentPart {
+	url := "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	return ImagePart(url)
+}
+
+// Roles of the authors of messages.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// NewMessages returns a conversation made of a system message followed by a
+// user message. The system message is left out if system is empty.
+func NewMessages(system, user string) []Message {
+	var messages []Message
+	if system != "" {
+		messages = append(messages, Message{Role: RoleSystem, Content: system})
+	}
+	return append(messages, Message{Role: RoleUser, Content: user})
+}
+
+// UserMessagef returns a user message whose content is formatted according
+// to a format specifier, as with fmt.Sprintf.
+func UserMessagef(format string, args ...interface{}) Message {
+	return Message{Role: RoleUser, Content: fmt.Sprintf(format, args...)}
+}
+
+// ToolResultMessage returns a tool message carrying the result of the tool
+// call with the given ID, to be sent back to the model.
+func ToolResultMessage(toolCallID, content string) Message {
+	return Message{Role: RoleTool, Content: content, ToolCallID: toolCallID}
+}
+
+// Tool is a tool the model may call. Only function tools are supported.
+type Tool struct {
+	Type     string      `json:"type"`
+	Function FunctionDef `json:"function"`
+}
+
+// FunctionDef describes a function the model may call. Parameters is a JSON
+// Schema object describing the arguments, such as a map[string]interface{}
+// or a json.RawMessage.
+type FunctionDef struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Parameters  interface{} `json:"parameters,omitempty"`
+}
+
+// FunctionTool returns a function tool for the given definition.
+func FunctionTool(fn FunctionDef) Tool {
+	return Tool{Type: "function", Function: fn}
+}
+
+// ToolCall is a request from the model to call a tool.
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall names the function to call and holds its arguments as a JSON
+// encoded object, which may need validating as the model can produce
+// invalid JSON.
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ChatRequest represents the JSON body sent to the chat completions
+// endpoint. It is assembled from a list of messages and a list of Options;
+// parameters left unset are omitted from the request body.
+type ChatRequest struct {
+	Params
+	Messages []Message `json:"messages"`
+}
+
+// Validate checks the parameters and messages of r for values the chat
+// endpoint is known to reject.
+func (r *ChatRequest) Validate() error {
+	if err := r.Params.Validate(); err != nil {
+		return err
+	}
+	if len(r.Messages) == 0 {
+		return errors.New("gpt3: at least one message is required")
+	}
+	if r.Suffix != "" || r.Echo || r.BestOf != nil || r.Logprobs != nil {
+		return errors.New("gpt3: suffix, echo, best_of and logprobs are not supported by chat completions")
+	}
+	return nil
+}
+
+// estimatedTokens estimates the tokens r consumes, messages and
+// completion. Without max_tokens the completion cannot be bounded and only
+// the messages are counted.
+func (r *ChatRequest) estimatedTokens() int {
+	n := r.completionBudget(0)
+	for _, m := range r.Messages {
+		// Every message carries a few tokens of framing.
+		n += 4 + estimateTokens(m.Content)
+		for _, part := range m.Parts {
+			n += estimateTokens(part.Text)
+			if part.ImageURL != nil {
+				n += imagePartTokens
+			}
+		}
+	}
+	return n
+}
+
+// imagePartTokens is the number of tokens an image in a message is assumed
+// to consume, the cost of a low detail image.
+const imagePartTokens = 85
+
+// ChatResponse represents the response of the chat completions endpoint.
+// Model names the model snapshot that served the request, such as
+// "gpt-4o-2024-08-06" for a request made with "gpt-4o".
+type ChatResponse struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []*ChatChoice `json:"choices"`
+	Usage   Usage         `json:"usage"`
+
+	// SystemFingerprint identifies the backend configuration that served
+	// the request. A change between requests with the same Seed means
+	// results may differ.
+	SystemFingerprint string `json:"system_fingerprint"`
+
+	// RateLimit holds the rate limit state reported with the response.
+	RateLimit RateLimitInfo `json:"-"`
+
+	// RequestID is the x-request-id header of the response, which OpenAI
+	// support asks for when investigating issues.
+	RequestID string `json:"-"`
+
+	// Retries is the number of times the request was retried before it
+	// succeeded.
+	Retries int `json:"-"`
+}
+
+func (r *ChatResponse) recordResponse(resp *http.Response) {
+	r.RateLimit = ParseRateLimit(resp)
+	r.RequestID = resp.Header.Get("x-request-id")
+}
+
+// FirstContent returns the content of the first choice's message, or the
+// empty string if the response has no choices.
+func (r *ChatResponse) FirstContent() string {
+	if r == nil || len(r.Choices) == 0 || r.Choices[0] == nil {
+		return ""
+	}
+	return r.Choices[0].Message.Content
+}
+
+// ChoiceByIndex returns the choice whose Index is i.
+func (r *ChatResponse) ChoiceByIndex(i int) (*ChatChoice, bool) {
+	if r == nil {
+		return nil, false
+	}
+	for _, choice := range r.Choices {
+		if choice != nil && choice.Index == i {
+			return choice, true
+		}
+	}
+	return nil, false
+}
+
+// CreatedTime returns the time the chat completion was created.
+func (r *ChatResponse) CreatedTime() time.Time {
+	return unixTime(r.Created)
+}
+
+// String returns the content of the first choice's message, so that
+// printing a response shows the reply.
+func (r *ChatResponse) String() string {
+	return r.FirstContent()
+}
+
+// finishReason returns the finish reason of the first choice.
+func (r *ChatResponse) finishReason() string {
+	if len(r.Choices) == 0 || r.Choices[0] == nil {
+		return ""
+	}
+	return r.Choices[0].FinishReason
+}
+
+// ChatChoice is a single message generated for a conversation.
+type ChatChoice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+// Complete creates a chat completion for the provided messages. It is
+// shorthand for CompleteContext with context.Background().
+func (s *ChatService) Complete(messages []Message, options ...Option) (*ChatResponse, error) {
+	return s.CompleteContext(context.Background(), messages, options...)
+}
+
+// CompleteContext creates a chat completion for the provided messages. The
+// sampling options are the same as for CompletionsService.CompleteContext,
+// and ctx governs the request in the same way.
+func (s *ChatService) CompleteContext(ctx context.Context, messages []Message, options ...Option) (*ChatResponse, error) {
+	r, err := s.newRequest(messages, options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	ctx, call := s.client.startCall(ctx, "gpt3.Chat", r.Model)
+	if err := s.client.waitRateLimit(ctx, r.estimatedTokens()); err != nil {
+		call.end(nil, nil, err)
+		return nil, err
+	}
+
+	req, err := s.client.newParamsRequest("POST", "chat/completions", r, &r.Params)
+	if err != nil {
+		call.end(nil, nil, err)
+		return nil, err
+	}
+
+	c := new(ChatResponse)
+	resp, err := s.client.Do(ctx, req, c)
+	call.finishReason = c.finishReason()
+	call.end(resp, &c.Usage, err)
+	if err != nil {
+		return nil, err
+	}
+	c.Retries = call.retries
+	return c, nil
+}
+
+// BuildRequest returns the HTTP request CompleteContext would send for
+// messages and options, without sending it.
+func (s *ChatService) BuildRequest(messages []Message, options ...Option) (*http.Request, error) {
+	r, err := s.newRequest(messages, options)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.newParamsRequest("POST", "chat/completions", r, &r.Params)
+}
+
+// chatChunk is a single event of a streamed chat completion.
+type chatChunk struct {
+	ID                string             `json:"id"`
+	Created           int64              `json:"created"`
+	Model             string             `json:"model"`
+	SystemFingerprint string             `json:"system_fingerprint"`
+	Choices           []*chatChunkChoice `json:"choices"`
+	Usage             *Usage             `json:"usage"`
+}
+
+// chatChunkChoice carries the part of a choice generated since the previous
+// chunk.
+type chatChunkChoice struct {
+	Index        int       `json:"index"`
+	Delta        chatDelta `json:"delta"`
+	FinishReason string    `json:"finish_reason"`
+}
+
+// chatDelta is the part of a message carried by a chunk.
+type chatDelta struct {
+	Role      string          `json:"role"`
+	Content   string          `json:"content"`
+	ToolCalls []toolCallDelta `json:"tool_calls"`
+}
+
+// toolCallDelta is the part of a tool call carried by a chunk. The first
+// delta of a call carries its ID, type and function name; the arguments
+// are spread over the following ones.
+type toolCallDelta struct {
+	Index    int          `json:"index"`
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// CompleteStream creates a chat completion for the provided messages and
+// streams it back as it is generated. fn is called with each piece of
+// content of the first choice as it arrives; returning an error from fn
+// ends the stream and CompleteStream returns that error. fn may be nil when
+// only the assembled response is wanted.
+//
+// Once the stream ends, the pieces of every choice are assembled into the
+// returned ChatResponse, whose choices carry the complete message and the
+// finish reason, so callers need not accumulate them. This includes tool
+// calls, whose arguments are streamed in fragments that are only valid
+// JSON once joined; fn is not called for them. Usage is only set
+// when the StreamIncludeUsage option is given. If ctx is done before the
+// stream ends, ctx.Err() is returned.
+//
+// If the stream fails after it has started, the error is returned together
+// with a response holding everything received until then, so a partial
+// answer can be salvaged. Its choices then lack a finish reason.
+func (s *ChatService) CompleteStream(ctx context.Context, messages []Message, fn func(delta string) error, options ...Option) (*ChatResponse, error) {
+	r, err := s.newRequest(messages, options)
+	if err != nil {
+		return nil, err
+	}
+	r.setStream()
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	ctx, call := s.client.startCall(ctx, "gpt3.ChatStream", r.Model)
+	c, err := s.stream(ctx, r, fn)
+	if err != nil {
+		call.end(nil, nil, err)
+		return c, err
+	}
+	call.finishReason = c.finishReason()
+	call.end(nil, &c.Usage, nil)
+	return c, nil
+}
+
+// stream sends r to the chat completions endpoint and assembles the
+// streamed response. Once the stream has started, the response assembled so
+// far is returned even on error.
+func (s *ChatService) stream(ctx context.Context, r *ChatRequest, fn func(delta string) error) (*ChatResponse, error) {
+	if err := s.client.waitRateLimit(ctx, r.estimatedTokens()); err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.newParamsRequest("POST", "chat/completions", r, &r.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := s.client.stream(ctx, req, r.idleTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	c := &ChatResponse{Object: "chat.completion"}
+	for {
+		chunk := new(chatChunk)
+		if err := stream.next(chunk); err != nil {
+			if err == io.EOF {
+				return c, nil
+			}
+			return c, err
+		}
+		if chunk.ID != "" {
+			c.ID, c.Created, c.Model = chunk.ID, chunk.Created, chunk.Model
+		}
+		if chunk.SystemFingerprint != "" {
+			c.SystemFingerprint = chunk.SystemFingerprint
+		}
+		if chunk.Usage != nil {
+			c.Usage = *chunk.Usage
+		}
+		for _, delta := range chunk.Choices {
+			if err := c.accumulate(delta); err != nil {
+				return c, err
+			}
+			if fn != nil && delta.Index == 0 && delta.Delta.Content != "" {
+				if err := fn(delta.Delta.Content); err != nil {
+					return c, err
+				}
+			}
+		}
+	}
+}
+
+// accumulate adds the piece of a choice carried by delta to r. It fails if
+// delta refers to a tool call that cannot follow the ones received so far.
+func (r *ChatResponse) accumulate(delta *chatChunkChoice) error {
+	var choice *ChatChoice
+	for _, c := range r.Choices {
+		if c.Index == delta.Index {
+			choice = c
+			break
+		}
+	}
+	if choice == nil {
+		choice = &ChatChoice{Index: delta.Index}
+		r.Choices = append(r.Choices, choice)
+		sort.Slice(r.Choices, func(i, j int) bool {
+			return r.Choices[i].Index < r.Choices[j].Index
+		})
+	}
+	if delta.Delta.Role != "" {
+		choice.Message.Role = delta.Delta.Role
+	}
+	choice.Message.Content += delta.Delta.Content
+	for _, d := range delta.Delta.ToolCalls {
+		// Tool calls are numbered from zero and each new one takes the
+		// next index, so anything else is a malformed stream.
+		if d.Index < 0 || d.Index > len(choice.Message.ToolCalls) {
+			return fmt.Errorf("gpt3: stream has tool call index %d out of range for choice %d", d.Index, delta.Index)
+		}
+		if d.Index == len(choice.Message.ToolCalls) {
+			choice.Message.ToolCalls = append(choice.Message.ToolCalls, ToolCall{})
+		}
+		call := &choice.Message.ToolCalls[d.Index]
+		if d.ID != "" {
+			call.ID = d.ID
+		}
+		if d.Type != "" {
+			call.Type = d.Type
+		}
+		if d.Function.Name != "" {
+			call.Function.Name = d.Function.Name
+		}
+		call.Function.Arguments += d.Function.Arguments
+	}
+	if delta.FinishReason != "" {
+		choice.FinishReason = delta.FinishReason
+	}
+	return nil
+}
+
+// newRequest builds and validates the request payload for messages.
+func (s *ChatService) newRequest(messages []Message, options []Option) (*ChatRequest, error) {
+	r := &ChatRequest{Messages: messages}
+	s.client.applyOptions(&r.Params, options)
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	s.client.logOptions(r)
+	return r, nil
+}
@@ -0,0 +1,299 @@
+package gpt3
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// A ClientOption configures a Client created by NewClientWithOptions.
+type ClientOption func(*clientOptions)
+
+// clientOptions accumulates ClientOptions before they are applied, so that
+// the order in which options are given does not matter.
+type clientOptions struct {
+	httpClient *http.Client
+	timeout    *time.Duration
+	transport  http.RoundTripper
+
+	organization string
+	baseURL      *url.URL
+	rateLimiter  *RateLimiter
+	tracer       Tracer
+	metrics      Metrics
+	logger       Logger
+	debug        bool
+	userAgent    string
+	compress     bool
+	cache        Cache
+	model        string
+	defaults     []Option
+	onRetry      func(attempt int, err error, delay time.Duration)
+	logUsage     bool
+	shouldRetry  func(resp *http.Response, err error, attempt int) bool
+	http2        *bool
+	insecure     bool
+	maxResponse  *int64
+	now          func() time.Time
+	sleep        func(time.Duration)
+}
+
+// WithHTTPClient sets the HTTP client used to communicate with the API. The
+// client is used as is unless other options require changes, in which case
+// a copy is modified and the original is left untouched.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) { o.httpClient = httpClient }
+}
+
+// WithTimeout sets the time limit for requests made by the client,
+// including reading the response body. The default is ten minutes.
+//
+// A timeout of zero disables the limit, leaving requests governed only by
+// the context passed to each call. When both a timeout and a context
+// deadline are set, whichever expires first aborts the request.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) { o.timeout = Duration(d) }
+}
+
+// WithTransport sets the RoundTripper used to send requests, for example
+// to add tracing or metrics. The library never replaces it. Combined with
+// WithHTTPClient, the transport is installed on a copy of that client.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(o *clientOptions) { o.transport = rt }
+}
+
+// WithHTTP2 enables or disables HTTP/2. When disabled, requests are sent
+// over HTTP/1.1, which can help with proxies that handle HTTP/2 poorly.
+// It only affects an *http.Transport; other RoundTrippers set with
+// WithTransport are used unchanged.
+func WithHTTP2(enabled bool) ClientOption {
+	return func(o *clientOptions) { o.http2 = &enabled }
+}
+
+// WithInsecureSkipVerify disables verification of the server's TLS
+// certificate, so that the client can talk to a local mock or proxy with a
+// self-signed certificate.
+//
+// This is unsafe: anyone on the network path can then intercept requests,
+// including the API key. Never use it against the real API or outside of
+// tests. Like WithHTTP2, it only affects an *http.Transport.
+func WithInsecureSkipVerify() ClientOption {
+	return func(o *clientOptions) { o.insecure = true }
+}
+
+// WithOrganization sets the organization that requests are billed to, for
+// accounts that belong to more than one.
+func WithOrganization(org string) ClientOption {
+	return func(o *clientOptions) { o.organization = org }
+}
+
+// WithBaseURL sets the base URL that API paths are resolved against, for
+// example "http://localhost:8080/v1". An unparseable URL is ignored and the
+// default is kept.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(o *clientOptions) {
+		if u, err := url.Parse(baseURL); err == nil {
+			o.baseURL = u
+		}
+	}
+}
+
+// WithRateLimit throttles completion and chat requests to
+// requestsPerMinute requests and tokensPerMinute tokens. Token usage is
+// estimated from the prompt and max_tokens before each request. A limit of
+// zero or less is not enforced.
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) ClientOption {
+	return func(o *clientOptions) { o.rateLimiter = NewRateLimiter(requestsPerMinute, tokensPerMinute) }
+}
+
+// WithTracer enables tracing of completion and chat calls with tracer.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(o *clientOptions) { o.tracer = tracer }
+}
+
+// WithMetrics reports the outcome of completion and chat calls to m.
+func WithMetrics(m Metrics) ClientOption {
+	return func(o *clientOptions) { o.metrics = m }
+}
+
+// WithLogger sets the Logger that receives diagnostic output.
+func WithLogger(logger Logger) ClientOption {
+	return func(o *clientOptions) { o.logger = logger }
+}
+
+// WithUsageLogging makes the client log the token usage of every
+// successful completion and chat call to its Logger. See Client.LogUsage.
+func WithUsageLogging() ClientOption {
+	return func(o *clientOptions) { o.logUsage = true }
+}
+
+// WithDebug logs the full request and response bodies to the client's
+// Logger, with credentials masked. Bodies may contain sensitive prompts and
+// output, so enable it only while debugging.
+func WithDebug() ClientOption {
+	return func(o *clientOptions) { o.debug = true }
+}
+
+// WithUserAgent sets the User-Agent sent with every request, replacing the
+// default "gpt3-go/<version>".
+func WithUserAgent(ua string) ClientOption {
+	return func(o *clientOptions) { o.userAgent = ua }
+}
+
+// WithRequestCompression gzips large JSON request bodies. Only enable it
+// for servers or gateways that accept compressed requests.
+func WithRequestCompression() ClientOption {
+	return func(o *clientOptions) { o.compress = true }
+}
+
+// WithCache serves repeated deterministic completion requests from cache,
+// such as an LRUCache.
+func WithCache(cache Cache) ClientOption {
+	return func(o *clientOptions) { o.cache = cache }
+}
+
+// WithShouldRetry sets the policy deciding which failed attempts are
+// retried, for example to also retry a gateway's 409 responses:
+//
+//	gpt3.WithShouldRetry(func(resp *http.Response, err error, attempt int) bool {
+//		if resp != nil && resp.StatusCode == http.StatusConflict {
+//			return true
+//		}
+//		return gpt3.DefaultShouldRetry(resp, err, attempt)
+//	})
+//
+// See Client.ShouldRetry.
+func WithShouldRetry(fn func(resp *http.Response, err error, attempt int) bool) ClientOption {
+	return func(o *clientOptions) { o.shouldRetry = fn }
+}
+
+// WithMaxResponseBytes limits the size of response bodies read into
+// memory. See Client.MaxResponseBytes.
+func WithMaxResponseBytes(n int64) ClientOption {
+	return func(o *clientOptions) { o.maxResponse = &n }
+}
+
+// WithClock replaces the clock the client uses to wait between retries and
+// to measure latency, so that tests can check backoff without waiting in
+// real time. now returns the current time and sleep blocks for the given
+// duration, typically advancing a fake clock. Either may be nil to keep the
+// real clock's function. Waits through sleep cannot be interrupted by
+// cancelling the request's context. Context deadlines are still compared
+// with the real time.
+func WithClock(now func() time.Time, sleep func(time.Duration)) ClientOption {
+	return func(o *clientOptions) { o.now, o.sleep = now, sleep }
+}
+
+// WithOnRetry sets a function called before every retry, for example to
+// count retries in a metrics system. See Client.OnRetry.
+func WithOnRetry(fn func(attempt int, err error, delay time.Duration)) ClientOption {
+	return func(o *clientOptions) { o.onRetry = fn }
+}
+
+// WithModel sets the model used by completion, chat and edit calls that do
+// not pass the Model option. See Client.Model.
+func WithModel(name string) ClientOption {
+	return func(o *clientOptions) { o.model = name }
+}
+
+// WithDefaultOptions sets options applied to every completion, chat and
+// edit request before the options of the call, so that shared parameters
+// such as the model need not be repeated. Options passed to a call win:
+// Temperature(0.9) on a call overrides a default Temperature(0).
+func WithDefaultOptions(options ...Option) ClientOption {
+	return func(o *clientOptions) { o.defaults = append(o.defaults, options...) }
+}
+
+// apply configures c according to o.
+func (o *clientOptions) apply(c *Client) {
+	if o.httpClient != nil {
+		c.client = o.httpClient
+	}
+	if o.timeout != nil || o.transport != nil {
+		hc := *c.client
+		if o.timeout != nil {
+			hc.Timeout = *o.timeout
+		}
+		if o.transport != nil {
+			hc.Transport = o.transport
+		}
+		c.client = &hc
+	}
+	if o.http2 != nil || o.insecure {
+		if t := httpTransport(c.client.Transport); t != nil {
+			if o.http2 != nil {
+				t.ForceAttemptHTTP2 = *o.http2
+				if !*o.http2 {
+					disableHTTP2(t)
+				}
+			}
+			if o.insecure {
+				if t.TLSClientConfig == nil {
+					t.TLSClientConfig = new(tls.Config)
+				} else {
+					t.TLSClientConfig = t.TLSClientConfig.Clone()
+				}
+				t.TLSClientConfig.InsecureSkipVerify = true
+			}
+			hc := *c.client
+			hc.Transport = t
+			c.client = &hc
+		}
+	}
+	c.Organization = o.organization
+	if o.baseURL != nil {
+		c.BaseURL = o.baseURL
+	}
+	c.RateLimiter = o.rateLimiter
+	c.Tracer = o.tracer
+	c.Metrics = o.metrics
+	c.Logger = o.logger
+	c.Debug = o.debug
+	c.CompressRequests = o.compress
+	c.Cache = o.cache
+	c.Model = o.model
+	c.DefaultOptions = o.defaults
+	c.OnRetry = o.onRetry
+	c.LogUsage = o.logUsage
+	c.ShouldRetry = o.shouldRetry
+	c.nowFunc = o.now
+	c.sleepFunc = o.sleep
+	if o.maxResponse != nil {
+		c.MaxResponseBytes = *o.maxResponse
+	}
+	if o.userAgent != "" {
+		c.UserAgent = o.userAgent
+	}
+}
+
+// httpTransport returns a copy of rt that can be modified, or nil if rt is
+// not an *http.Transport. A nil rt stands for http.DefaultTransport.
+func httpTransport(rt http.RoundTripper) *http.Transport {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil
+	}
+	return t.Clone()
+}
+
+// disableHTTP2 makes t use HTTP/1.1 only. A non-nil, empty TLSNextProto
+// turns off HTTP/2 support, and h2 must no longer be offered during the TLS
+// handshake, as a transport that has been used may already offer it.
+func disableHTTP2(t *http.Transport) {
+	t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	if t.TLSClientConfig == nil {
+		return
+	}
+	t.TLSClientConfig = t.TLSClientConfig.Clone()
+	var protos []string
+	for _, p := range t.TLSClientConfig.NextProtos {
+		if p != "h2" {
+			protos = append(protos, p)
+		}
+	}
+	t.TLSClientConfig.NextProtos = protos
+}
@@ -0,0 +1,584 @@
+package gpt3
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// DefaultModel is a completions model that callers can pass to the Model
+// option when they have no particular model in mind.
+const DefaultModel = "gpt-3.5-turbo-instruct"
+
+// defaultCompletionMaxTokens is the max_tokens the completions endpoint
+// uses when none is given.
+const defaultCompletionMaxTokens = 16
+
+// CompletionsService handles communication with the completion related
+// methods of the OpenAI API.
+type CompletionsService struct {
+	client *Client
+}
+
+// Completer is the interface implemented by CompletionsService. Code that
+// only needs to create completions can depend on it and substitute a fake in
+// tests.
+type Completer interface {
+	Complete(prompt string, options ...Option) (*Completions, error)
+	CompleteContext(ctx context.Context, prompt string, options ...Option) (*Completions, error)
+	CompleteBatch(ctx context.Context, prompts []string, options ...Option) (*Completions, error)
+	CompleteStream(ctx context.Context, prompt string, fn func(chunk *Completions) error, options ...Option) error
+	CompleteStreamChan(ctx context.Context, prompt string, options ...Option) (<-chan StreamChunk, <-chan error)
+	CompleteStreamTo(ctx context.Context, w io.Writer, prompt string, options ...Option) error
+	CompleteStreamCollect(ctx context.Context, prompt string, fn func(chunk *Completions) error, options ...Option) (*Completions, error)
+}
+
+var _ Completer = (*CompletionsService)(nil)
+
+// CompletionRequest represents the JSON body sent to the completions
+// endpoint. It is assembled from a prompt and a list of Options; parameters
+// left unset are omitted from the request body. Prompt is either a string
+// or, for batch requests, a []string.
+type CompletionRequest struct {
+	Params
+	Prompt interface{} `json:"prompt"`
+}
+
+// Validate checks r for values the completions endpoint is known to
+// reject.
+func (r *CompletionRequest) Validate() error {
+	if err := r.Params.Validate(); err != nil {
+		return err
+	}
+	if len(r.Tools) > 0 || r.ToolChoice != nil {
+		return errors.New("gpt3: tools are only supported by chat completions")
+	}
+	return nil
+}
+
+// estimatedTokens estimates the tokens r consumes, prompt and completion.
+func (r *CompletionRequest) estimatedTokens() int {
+	n := r.completionBudget(defaultCompletionMaxTokens)
+	switch prompt := r.Prompt.(type) {
+	case string:
+		n += estimateTokens(prompt)
+	case []string:
+		n *= len(prompt)
+		for _, p := range prompt {
+			n += estimateTokens(p)
+		}
+	}
+	return n
+}
+
+// Completions represents the response of the completions endpoint. Model
+// names the model snapshot that served the request, which may be more
+// specific than the alias that was requested.
+type Completions struct {
+	ID      string    `json:"id"`
+	Object  string    `json:"object"`
+	Created int64     `json:"created"`
+	Model   string    `json:"model"`
+	Choices []*Choice `json:"choices"`
+	Usage   Usage     `json:"usage"`
+
+	// SystemFingerprint identifies the backend configuration that served
+	// the request. A change between requests with the same Seed means
+	// results may differ.
+	SystemFingerprint string `json:"system_fingerprint"`
+
+	// RateLimit holds the rate limit state reported with the response.
+	RateLimit RateLimitInfo `json:"-"`
+
+	// RequestID is the x-request-id header of the response, which OpenAI
+	// support asks for when investigating issues.
+	RequestID string `json:"-"`
+
+	// Retries is the number of times the request was retried before it
+	// succeeded.
+	Retries int `json:"-"`
+}
+
+func (c *Completions) recordResponse(resp *http.Response) {
+	c.RateLimit = ParseRateLimit(resp)
+	c.RequestID = resp.Header.Get("x-request-id")
+}
+
+// FirstText returns the text of the first choice, or the empty string if the
+// response has no choices.
+func (c *Completions) FirstText() string {
+	if c == nil || len(c.Choices) == 0 || c.Choices[0] == nil {
+		return ""
+	}
+	return c.Choices[0].Text
+}
+
+// ChoiceByIndex returns the choice whose Index is i. Indices are those
+// assigned by the API: with N set to n they run from 0 to n-1, and in a
+// batch choice j of prompt k has index k*n+j.
+func (c *Completions) ChoiceByIndex(i int) (*Choice, bool) {
+	if c == nil {
+		return nil, false
+	}
+	for _, choice := range c.Choices {
+		if choice != nil && choice.Index == i {
+			return choice, true
+		}
+	}
+	return nil, false
+}
+
+// CreatedTime returns the time the completion was created.
+func (c *Completions) CreatedTime() time.Time {
+	return unixTime(c.Created)
+}
+
+// String returns the text of the first choice, so that printing a response
+// shows the generated text. Use json.Marshal for the full response in the
+// shape the API returned it.
+func (c *Completions) String() string {
+	return c.FirstText()
+}
+
+// finishReason returns the finish reason of the first choice.
+func (c *Completions) finishReason() string {
+	if len(c.Choices) == 0 || c.Choices[0] == nil {
+		return ""
+	}
+	return c.Choices[0].FinishReason
+}
+
+// Best returns the choice with the highest mean log probability per token,
+// the same measure the API uses for best_of. It fails if the response has
+// no choices or was requested without the Logprobs option.
+func (c *Completions) Best() (*Choice, error) {
+	if c == nil || len(c.Choices) == 0 {
+		return nil, errors.New("gpt3: response has no choices")
+	}
+	var best *Choice
+	bestScore := math.Inf(-1)
+	for _, choice := range c.Choices {
+		if choice == nil {
+			continue
+		}
+		if choice.Logprobs == nil {
+			return nil, errors.New("gpt3: response has no log probabilities; use the Logprobs option")
+		}
+		if score := choice.Logprobs.mean(); best == nil || score > bestScore {
+			best, bestScore = choice, score
+		}
+	}
+	if best == nil {
+		return nil, errors.New("gpt3: response has no choices")
+	}
+	return best, nil
+}
+
+// Choice is a single completion generated for a prompt.
+type Choice struct {
+	Text     string          `json:"text"`
+	Index    int             `json:"index"`
+	Logprobs *ChoiceLogprobs `json:"logprobs"`
+
+	// FinishReason tells why generation stopped, e.g. FinishReasonStop or
+	// FinishReasonLength. It is empty on intermediate stream chunks.
+	FinishReason string `json:"finish_reason"`
+}
+
+// Values of the finish reason reported on each choice.
+const (
+	// FinishReasonStop means the model reached a natural stopping point or
+	// a stop sequence.
+	FinishReasonStop = "stop"
+
+	// FinishReasonLength means the output was truncated by max_tokens or
+	// the context window; retrying with a larger budget may help.
+	FinishReasonLength = "length"
+
+	// FinishReasonContentFilter means content was omitted by the content
+	// filter.
+	FinishReasonContentFilter = "content_filter"
+)
+
+// ChoiceLogprobs holds the log probabilities of the tokens of a choice. It
+// is only populated when log probabilities were requested with the Logprobs
+// option. The slices are parallel: element i of each describes the i-th
+// token.
+type ChoiceLogprobs struct {
+	Tokens        []string             `json:"tokens"`
+	TokenLogprobs []float64            `json:"token_logprobs"`
+	TopLogprobs   []map[string]float64 `json:"top_logprobs"`
+
+	// TextOffset holds the position of each token in Choice.Text, counted
+	// in characters (runes) rather than bytes. With Echo enabled, Text
+	// starts with the prompt, so the offsets of the prompt's tokens point
+	// into the original prompt and the log probability of every prompt
+	// character can be recovered. The first token of an echoed prompt has
+	// no log probability, reported as 0.
+	TextOffset []int `json:"text_offset"`
+}
+
+// mean returns the mean log probability of the tokens, or negative
+// infinity if there are none.
+func (l *ChoiceLogprobs) mean() float64 {
+	if len(l.TokenLogprobs) == 0 {
+		return math.Inf(-1)
+	}
+	var sum float64
+	for _, lp := range l.TokenLogprobs {
+		sum += lp
+	}
+	return sum / float64(len(l.TokenLogprobs))
+}
+
+// Usage reports the number of tokens consumed by a request. It is left zeroed
+// when the API does not report usage.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Complete creates a completion for the provided prompt. It is shorthand for
+// CompleteContext with context.Background().
+func (s *CompletionsService) Complete(prompt string, options ...Option) (*Completions, error) {
+	return s.CompleteContext(context.Background(), prompt, options...)
+}
+
+// CompleteContext creates a completion for the provided prompt. The prompt and
+// options are sent as the JSON body of a POST request.
+//
+// The request is bound to ctx, so cancelling ctx aborts the call and a
+// deadline on ctx limits how long it may take; in both cases ctx.Err() is
+// returned. The timeout of the underlying http.Client still applies, so the
+// shorter of the two wins.
+func (s *CompletionsService) CompleteContext(ctx context.Context, prompt string, options ...Option) (*Completions, error) {
+	r, err := s.newRequest(prompt, options)
+	if err != nil {
+		return nil, err
+	}
+	return s.create(ctx, r)
+}
+
+// CompleteText creates a completion for the provided prompt and returns
+// the text of the first choice. It fails if the response has no choices.
+func (s *CompletionsService) CompleteText(ctx context.Context, prompt string, options ...Option) (string, error) {
+	c, err := s.CompleteContext(ctx, prompt, options...)
+	if err != nil {
+		return "", err
+	}
+	if len(c.Choices) == 0 || c.Choices[0] == nil {
+		return "", errors.New("gpt3: response has no choices")
+	}
+	return c.Choices[0].Text, nil
+}
+
+// CompleteStream creates a completion for the provided prompt and streams
+// it back as it is generated. fn is called with every chunk received, each
+// holding the text generated since the previous one. CompleteStream returns
+// once the stream ends, ctx is done, or fn returns an error, which is then
+// returned as is. The BestOf option cannot be combined with streaming.
+//
+// The last chunk of each choice carries its FinishReason. When a stop
+// sequence ends the output, the reason is FinishReasonStop and the stop
+// sequence is not included in the text.
+//
+// If the stream fails part way, the chunks already passed to fn are all
+// that is received; callers wanting to salvage a partial answer should keep
+// them as they arrive.
+//
+// Streamed chunks carry no token usage unless the StreamIncludeUsage option
+// is given, in which case the last chunk has no choices and holds the usage
+// of the whole request.
+//
+// The timeout of the underlying http.Client covers the whole stream.
+func (s *CompletionsService) CompleteStream(ctx context.Context, prompt string, fn func(chunk *Completions) error, options ...Option) error {
+	r, err := s.newRequest(prompt, options)
+	if err != nil {
+		return err
+	}
+	if r.BestOf != nil && *r.BestOf > 1 {
+		return errors.New("gpt3: best_of cannot be used when streaming")
+	}
+	r.setStream()
+
+	var usage *Usage
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	ctx, call := s.client.startCall(ctx, "gpt3.CompleteStream", r.Model)
+	err = s.stream(ctx, r, func(chunk *Completions) error {
+		if chunk.Usage.TotalTokens > 0 {
+			usage = &chunk.Usage
+		}
+		if reason := chunk.finishReason(); reason != "" {
+			call.finishReason = reason
+		}
+		return fn(chunk)
+	})
+	call.end(nil, usage, err)
+	return err
+}
+
+// stream sends r to the completions endpoint and passes each chunk of the
+// streamed response to fn.
+func (s *CompletionsService) stream(ctx context.Context, r *CompletionRequest, fn func(chunk *Completions) error) error {
+	if err := s.client.waitRateLimit(ctx, r.estimatedTokens()); err != nil {
+		return err
+	}
+
+	req, err := s.client.newParamsRequest("POST", "completions", r, &r.Params)
+	if err != nil {
+		return err
+	}
+
+	stream, err := s.client.stream(ctx, req, r.idleTimeout)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	for {
+		chunk := new(Completions)
+		if err := stream.next(chunk); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+}
+
+// CompleteStreamCollect is like CompleteStream but also assembles the
+// chunks into a single Completions, returned once the stream ends, holding
+// the full text, log probabilities and finish reason of every choice, and
+// the usage if StreamIncludeUsage was given. fn may be nil. If the stream
+// fails part way, the result assembled so far is returned with the error.
+func (s *CompletionsService) CompleteStreamCollect(ctx context.Context, prompt string, fn func(chunk *Completions) error, options ...Option) (*Completions, error) {
+	c := new(Completions)
+	err := s.CompleteStream(ctx, prompt, func(chunk *Completions) error {
+		c.accumulate(chunk)
+		if fn != nil {
+			return fn(chunk)
+		}
+		return nil
+	}, options...)
+	return c, err
+}
+
+// accumulate adds a streamed chunk to c.
+func (c *Completions) accumulate(chunk *Completions) {
+	if chunk.ID != "" {
+		c.ID, c.Object, c.Created, c.Model = chunk.ID, chunk.Object, chunk.Created, chunk.Model
+	}
+	if chunk.SystemFingerprint != "" {
+		c.SystemFingerprint = chunk.SystemFingerprint
+	}
+	if chunk.Usage.TotalTokens > 0 {
+		c.Usage = chunk.Usage
+	}
+	for _, delta := range chunk.Choices {
+		var choice *Choice
+		for _, ch := range c.Choices {
+			if ch.Index == delta.Index {
+				choice = ch
+				break
+			}
+		}
+		if choice == nil {
+			choice = &Choice{Index: delta.Index}
+			c.Choices = append(c.Choices, choice)
+			sort.Slice(c.Choices, func(i, j int) bool {
+				return c.Choices[i].Index < c.Choices[j].Index
+			})
+		}
+		choice.Text += delta.Text
+		if delta.FinishReason != "" {
+			choice.FinishReason = delta.FinishReason
+		}
+		if lp := delta.Logprobs; lp != nil {
+			if choice.Logprobs == nil {
+				choice.Logprobs = new(ChoiceLogprobs)
+			}
+			choice.Logprobs.Tokens = append(choice.Logprobs.Tokens, lp.Tokens...)
+			choice.Logprobs.TokenLogprobs = append(choice.Logprobs.TokenLogprobs, lp.TokenLogprobs...)
+			choice.Logprobs.TopLogprobs = append(choice.Logprobs.TopLogprobs, lp.TopLogprobs...)
+			choice.Logprobs.TextOffset = append(choice.Logprobs.TextOffset, lp.TextOffset...)
+		}
+	}
+}
+
+// CompleteBatch creates completions for several prompts in a single
+// request.
+//
+// The returned choices are sorted by Index. With N set to n (1 by default),
+// choice i*n+j is the j-th completion of prompts[i], so outputs can be
+// matched back to their inputs by index.
+func (s *CompletionsService) CompleteBatch(ctx context.Context, prompts []string, options ...Option) (*Completions, error) {
+	if len(prompts) == 0 {
+		return nil, errors.New("gpt3: at least one prompt is required")
+	}
+	r, err := s.newRequest(prompts, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.create(ctx, r)
+}
+
+// StreamChunk is a piece of text delivered by CompleteStreamChan. Index
+// identifies the choice the text belongs to. FinishReason is set on the
+// last chunk of a choice, for example to FinishReasonStop when a stop
+// sequence was reached; the stop sequence itself is never part of Text.
+type StreamChunk struct {
+	Text         string
+	Index        int
+	FinishReason string
+}
+
+// CompleteStreamChan is like CompleteStream but delivers the generated text
+// on a channel. The error channel receives at most one value, the error that
+// ended the stream, if any. Both channels are closed when the stream ends.
+//
+// If the stream fails part way, the chunks delivered before the error make
+// up the partial answer.
+//
+// Callers that stop reading chunks early must cancel ctx so the stream is
+// torn down and its goroutine exits.
+func (s *CompletionsService) CompleteStreamChan(ctx context.Context, prompt string, options ...Option) (<-chan StreamChunk, <-chan error) {
+	chunks := make(chan StreamChunk)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(chunks)
+
+		err := s.CompleteStream(ctx, prompt, func(c *Completions) error {
+			for _, choice := range c.Choices {
+				select {
+				case chunks <- StreamChunk{Text: choice.Text, Index: choice.Index, FinishReason: choice.FinishReason}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		}, options...)
+		if err != nil {
+			errc <- err
+		}
+	}()
+
+	return chunks, errc
+}
+
+// CompleteStreamTo is like CompleteStream but writes the generated text to w
+// as it arrives, flushing w after every write if it has a Flush method, as
+// bufio.Writer and http.ResponseWriter do. Only the text of the first choice
+// is written. A write error ends the stream and is returned. If the stream
+// fails part way, w keeps the text written before the error.
+func (s *CompletionsService) CompleteStreamTo(ctx context.Context, w io.Writer, prompt string, options ...Option) error {
+	return s.CompleteStream(ctx, prompt, func(c *Completions) error {
+		for _, choice := range c.Choices {
+			if choice.Index != 0 || choice.Text == "" {
+				continue
+			}
+			if _, err := io.WriteString(w, choice.Text); err != nil {
+				return err
+			}
+			if err := flush(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, options...)
+}
+
+// flush flushes w if it buffers its output.
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case http.Flusher:
+		f.Flush()
+	}
+	return nil
+}
+
+// BuildRequest returns the HTTP request CompleteContext would send for
+// prompt and options, without sending it. It is meant for inspecting the
+// serialized parameters, for example in tests.
+func (s *CompletionsService) BuildRequest(prompt string, options ...Option) (*http.Request, error) {
+	r, err := s.newRequest(prompt, options)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.newParamsRequest("POST", "completions", r, &r.Params)
+}
+
+// create sends r to the completions endpoint.
+func (s *CompletionsService) create(ctx context.Context, r *CompletionRequest) (*Completions, error) {
+	var key string
+	if cache := s.client.Cache; cache != nil && s.client.cachePolicy()(r) {
+		var ok bool
+		if key, ok = s.client.cacheKey(r); ok {
+			if c, ok := cache.Get(key); ok {
+				return c, nil
+			}
+		}
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	ctx, call := s.client.startCall(ctx, "gpt3.Complete", r.Model)
+	if err := s.client.waitRateLimit(ctx, r.estimatedTokens()); err != nil {
+		call.end(nil, nil, err)
+		return nil, err
+	}
+
+	req, err := s.client.newParamsRequest("POST", "completions", r, &r.Params)
+	if err != nil {
+		call.end(nil, nil, err)
+		return nil, err
+	}
+
+	c := new(Completions)
+	resp, err := s.client.Do(ctx, req, c)
+	call.finishReason = c.finishReason()
+	call.end(resp, &c.Usage, err)
+	if err != nil {
+		return nil, err
+	}
+	c.Retries = call.retries
+	// Sort before the response can be shared through the cache.
+	sort.SliceStable(c.Choices, func(i, j int) bool {
+		return c.Choices[i].Index < c.Choices[j].Index
+	})
+	if key != "" {
+		s.client.Cache.Set(key, c)
+	}
+	return c, nil
+}
+
+// newRequest builds and validates the request payload for prompt.
+func (s *CompletionsService) newRequest(prompt interface{}, options []Option) (*CompletionRequest, error) {
+	r := &CompletionRequest{Prompt: prompt}
+	s.client.applyOptions(&r.Params, options)
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if r.BestOf != nil && *r.BestOf > bestOfWarnThreshold {
+		s.client.logf("gpt3: best_of=%d generates %d completions server-side and consumes quota quickly", *r.BestOf, *r.BestOf)
+	} else if budget := r.completionBudget(defaultCompletionMaxTokens); r.BestOf != nil && budget > bestOfTokensWarnThreshold {
+		s.client.logf("gpt3: best_of=%d with max_tokens may generate up to %d tokens server-side", *r.BestOf, budget)
+	}
+	s.client.logOptions(r)
+	return r, nil
+}
@@ -0,0 +1,72 @@
+package gpt3
+
+import (
+	"context"
+	"sync"
+)
+
+// Conversation holds the history of a chat and sends it with every new
+// user message, so callers need not track it themselves. It is safe for
+// concurrent use; concurrent calls to Say are serialized.
+type Conversation struct {
+	client *Client
+
+	mu       sync.Mutex
+	system   string
+	messages []Message
+}
+
+// NewConversation returns a conversation using client. If system is not
+// empty it is sent as the system message at the start of the history.
+func NewConversation(client *Client, system string) *Conversation {
+	return &Conversation{client: client, system: system}
+}
+
+// SetSystem replaces the system message. It applies to the following calls
+// to Say without clearing the history.
+func (c *Conversation) SetSystem(system string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.system = system
+}
+
+// Say sends userText along with the history and returns the assistant's
+// reply. Both are added to the history only if the call succeeds, so a
+// failed call can simply be retried.
+func (c *Conversation) Say(ctx context.Context, userText string, options ...Option) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	user := Message{Role: RoleUser, Content: userText}
+	messages := make([]Message, 0, len(c.messages)+2)
+	if c.system != "" {
+		messages = append(messages, Message{Role: RoleSystem, Content: c.system})
+	}
+	messages = append(messages, c.messages...)
+	messages = append(messages, user)
+
+	resp, err := c.client.Chat.CompleteContext(ctx, messages, options...)
+	if err != nil {
+		return "", err
+	}
+	reply := Message{Role: RoleAssistant, Content: resp.FirstContent()}
+	if len(resp.Choices) > 0 && resp.Choices[0] != nil {
+		reply = resp.Choices[0].Message
+	}
+	c.messages = append(c.messages, user, reply)
+	return reply.Content, nil
+}
+
+// Messages returns a copy of the history, without the system message.
+func (c *Conversation) Messages() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// Reset clears the history, keeping the system message.
+func (c *Conversation) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = nil
+}
@@ -0,0 +1,61 @@
+package gpt3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Price is the cost of a model in US dollars per 1,000 tokens.
+type Price struct {
+	Input  float64 // per 1K prompt tokens
+	Output float64 // per 1K completion tokens
+}
+
+// Prices maps model name prefixes to their prices; the longest matching
+// prefix wins. The defaults reflect published list prices at the time of
+// writing and will drift, so override entries to match your contract.
+var Prices = map[string]Price{
+	"gpt-3.5-turbo-instruct": {Input: 0.0015, Output: 0.002},
+	"gpt-3.5-turbo":          {Input: 0.0005, Output: 0.0015},
+	"gpt-4":                  {Input: 0.03, Output: 0.06},
+	"gpt-4-32k":              {Input: 0.06, Output: 0.12},
+	"gpt-4-turbo":            {Input: 0.01, Output: 0.03},
+	"gpt-4o":                 {Input: 0.0025, Output: 0.01},
+	"gpt-4o-mini":            {Input: 0.00015, Output: 0.0006},
+	"davinci-002":            {Input: 0.002, Output: 0.002},
+	"babbage-002":            {Input: 0.0004, Output: 0.0004},
+	"text-embedding-3-small": {Input: 0.00002},
+	"text-embedding-3-large": {Input: 0.00013},
+	"text-embedding-ada-002": {Input: 0.0001},
+}
+
+// Cost returns the cost in US dollars of a request to model that consumed
+// usage, according to Prices. An error is returned for unknown models.
+func Cost(model string, usage Usage) (float64, error) {
+	best := ""
+	for prefix := range Prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return 0, fmt.Errorf("gpt3: unknown price for model %q", model)
+	}
+	p := Prices[best]
+	return (float64(usage.PromptTokens)*p.Input + float64(usage.CompletionTokens)*p.Output) / 1000, nil
+}
+
+// Cost returns the cost in US dollars of the request that produced c.
+func (c *Completions) Cost() (float64, error) {
+	return Cost(c.Model, c.Usage)
+}
+
+// Cost returns the cost in US dollars of the request that produced r.
+func (r *ChatResponse) Cost() (float64, error) {
+	return Cost(r.Model, r.Usage)
+}
+
+// Cost returns the cost in US dollars of the request that produced r.
+func (r *EmbeddingResponse) Cost() (float64, error) {
+	return Cost(r.Model, r.Usage)
+}
@@ -0,0 +1,77 @@
+package gpt3
+
+import (
+	"context"
+	"time"
+)
+
+// EditsService handles communication with the edit related methods of the
+// OpenAI API. OpenAI has deprecated the edits endpoint in favor of chat
+// completions, and it is only served by some deployments and gateways.
+type EditsService struct {
+	client *Client
+}
+
+// EditRequest represents the JSON body sent to the edits endpoint. Of the
+// Options, only Model, N, Temperature and TopP apply; the endpoint rejects
+// the other parameters, so they are not sent.
+type EditRequest struct {
+	Model       string   `json:"model"`
+	Input       string   `json:"input,omitempty"`
+	Instruction string   `json:"instruction"`
+	N           *int     `json:"n,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+}
+
+// EditResponse represents the response of the edits endpoint.
+type EditResponse struct {
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Choices []*EditChoice `json:"choices"`
+	Usage   Usage         `json:"usage"`
+}
+
+// CreatedTime returns the time the edit was created.
+func (r *EditResponse) CreatedTime() time.Time {
+	return unixTime(r.Created)
+}
+
+// EditChoice is a single edited version of the input.
+type EditChoice struct {
+	Text  string `json:"text"`
+	Index int    `json:"index"`
+}
+
+// Edit returns versions of input rewritten according to instruction, for
+// example "Fix the spelling mistakes".
+func (s *EditsService) Edit(ctx context.Context, input, instruction string, options ...Option) (*EditResponse, error) {
+	var p Params
+	s.client.applyOptions(&p, options)
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	r := &EditRequest{
+		Model:       p.Model,
+		Input:       input,
+		Instruction: instruction,
+		N:           p.N,
+		Temperature: p.Temperature,
+		TopP:        p.TopP,
+	}
+
+	req, err := s.client.newParamsRequest("POST", "edits", r, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	e := new(EditResponse)
+	_, err = s.client.Do(ctx, req, e)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
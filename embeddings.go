@@ -0,0 +1,59 @@
+package gpt3
+
+import (
+	"context"
+	"errors"
+)
+
+// EmbeddingsService handles communication with the embedding related
+// methods of the OpenAI API.
+type EmbeddingsService struct {
+	client *Client
+}
+
+// EmbeddingRequest represents the JSON body sent to the embeddings
+// endpoint.
+type EmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbeddingResponse represents the response of the embeddings endpoint.
+type EmbeddingResponse struct {
+	Object string       `json:"object"`
+	Data   []*Embedding `json:"data"`
+	Model  string       `json:"model"`
+	Usage  Usage        `json:"usage"`
+}
+
+// Embedding is the vector representation of a single input. Index is the
+// position of the input in the request.
+type Embedding struct {
+	Object    string    `json:"object"`
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// Create returns an embedding vector for each of the inputs, computed by the
+// given model, such as "text-embedding-3-small". All inputs are sent in a
+// single request.
+func (s *EmbeddingsService) Create(ctx context.Context, input []string, model string) (*EmbeddingResponse, error) {
+	if model == "" {
+		return nil, errors.New("gpt3: no model specified")
+	}
+	if len(input) == 0 {
+		return nil, errors.New("gpt3: at least one input is required")
+	}
+
+	req, err := s.client.NewRequest("POST", "embeddings", &EmbeddingRequest{Model: model, Input: input})
+	if err != nil {
+		return nil, err
+	}
+
+	e := new(EmbeddingResponse)
+	_, err = s.client.Do(ctx, req, e)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,107 @@
+package gpt3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+)
+
+// FilesService handles communication with the file related methods of the
+// OpenAI API. Files hold training data for fine-tuning among other uses.
+type FilesService struct {
+	client *Client
+}
+
+// File describes a file uploaded to the API.
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+}
+
+// FileList represents the response of the list files endpoint.
+type FileList struct {
+	Object  string  `json:"object"`
+	Data    []*File `json:"data"`
+	HasMore bool    `json:"has_more"`
+}
+
+// Upload uploads the contents of r as filename for the given purpose, such
+// as "fine-tune" for fine-tuning training data in JSON Lines format.
+func (s *FilesService) Upload(ctx context.Context, r io.Reader, filename, purpose string) (*File, error) {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	if err := w.WriteField("purpose", purpose); err != nil {
+		return nil, err
+	}
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewUploadRequest("POST", "files", body, w.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+
+	f := new(File)
+	_, err = s.client.Do(ctx, req, f)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// List returns the first page of files uploaded by the organization. Use
+// ListAll to fetch them all.
+func (s *FilesService) List(ctx context.Context) (*FileList, error) {
+	req, err := s.client.NewRequest("GET", "files", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(FileList)
+	_, err = s.client.Do(ctx, req, l)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Content writes the contents of the file with the given ID to w, for
+// example the results of a batch.
+func (s *FilesService) Content(ctx context.Context, id string, w io.Writer) error {
+	u := fmt.Sprintf("files/%v/content", url.PathEscape(id))
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(ctx, req, w)
+	return err
+}
+
+// Delete deletes the file with the given ID.
+func (s *FilesService) Delete(ctx context.Context, id string) error {
+	u := fmt.Sprintf("files/%v", url.PathEscape(id))
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(ctx, req, nil)
+	return err
+}
@@ -0,0 +1,198 @@
+package gpt3
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// FineTuningService handles communication with the fine-tuning related
+// methods of the OpenAI API.
+type FineTuningService struct {
+	client *Client
+}
+
+// FineTuneRequest represents the JSON body sent to create a fine-tuning
+// job. Fields left unset are omitted and take the API defaults.
+type FineTuneRequest struct {
+	TrainingFile    string           `json:"training_file"`
+	Model           string           `json:"model"`
+	ValidationFile  string           `json:"validation_file,omitempty"`
+	Suffix          string           `json:"suffix,omitempty"`
+	Seed            *int             `json:"seed,omitempty"`
+	Hyperparameters *Hyperparameters `json:"hyperparameters,omitempty"`
+}
+
+// Hyperparameters are the training parameters of a fine-tuning job. Each
+// is either the string "auto", letting the API pick a value, or a number.
+type Hyperparameters struct {
+	NEpochs                interface{} `json:"n_epochs,omitempty"`
+	BatchSize              interface{} `json:"batch_size,omitempty"`
+	LearningRateMultiplier interface{} `json:"learning_rate_multiplier,omitempty"`
+}
+
+// A FineTuneOption sets a parameter on a FineTuneRequest.
+type FineTuneOption func(*FineTuneRequest)
+
+// FineTuneValidationFile sets the ID of an uploaded file with validation
+// data, used to report validation metrics during training.
+func FineTuneValidationFile(fileID string) FineTuneOption {
+	return func(r *FineTuneRequest) { r.ValidationFile = fileID }
+}
+
+// FineTuneSuffix sets a string of up to 18 characters added to the name of
+// the fine-tuned model.
+func FineTuneSuffix(suffix string) FineTuneOption {
+	return func(r *FineTuneRequest) { r.Suffix = suffix }
+}
+
+// FineTuneSeed sets the seed controlling the reproducibility of the job.
+func FineTuneSeed(seed int) FineTuneOption {
+	return func(r *FineTuneRequest) { r.Seed = Int(seed) }
+}
+
+// FineTuneEpochs sets the number of epochs to train for.
+func FineTuneEpochs(n int) FineTuneOption {
+	return func(r *FineTuneRequest) { r.hyperparameters().NEpochs = n }
+}
+
+// FineTuneBatchSize sets the number of examples in each batch.
+func FineTuneBatchSize(n int) FineTuneOption {
+	return func(r *FineTuneRequest) { r.hyperparameters().BatchSize = n }
+}
+
+// FineTuneLearningRateMultiplier sets the scaling factor for the learning
+// rate.
+func FineTuneLearningRateMultiplier(m float64) FineTuneOption {
+	return func(r *FineTuneRequest) { r.hyperparameters().LearningRateMultiplier = m }
+}
+
+func (r *FineTuneRequest) hyperparameters() *Hyperparameters {
+	if r.Hyperparameters == nil {
+		r.Hyperparameters = new(Hyperparameters)
+	}
+	return r.Hyperparameters
+}
+
+// Values of the status of a fine-tuning job.
+const (
+	FineTuneValidatingFiles = "validating_files"
+	FineTuneQueued          = "queued"
+	FineTuneRunning         = "running"
+	FineTuneSucceeded       = "succeeded"
+	FineTuneFailed          = "failed"
+	FineTuneCancelled       = "cancelled"
+)
+
+// FineTuneJob describes a fine-tuning job.
+type FineTuneJob struct {
+	ID              string          `json:"id"`
+	Object          string          `json:"object"`
+	CreatedAt       int64           `json:"created_at"`
+	FinishedAt      int64           `json:"finished_at"`
+	Model           string          `json:"model"`
+	FineTunedModel  string          `json:"fine_tuned_model"`
+	OrganizationID  string          `json:"organization_id"`
+	Status          string          `json:"status"`
+	Hyperparameters Hyperparameters `json:"hyperparameters"`
+	TrainingFile    string          `json:"training_file"`
+	ValidationFile  string          `json:"validation_file"`
+	ResultFiles     []string        `json:"result_files"`
+	TrainedTokens   int             `json:"trained_tokens"`
+	Error           *FineTuneError  `json:"error"`
+}
+
+// FineTuneError explains why a fine-tuning job failed.
+type FineTuneError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Param   string `json:"param"`
+}
+
+// Done reports whether the job has finished, successfully or not, so that
+// polling can stop. FineTunedModel is set once a job has succeeded.
+func (j *FineTuneJob) Done() bool {
+	switch j.Status {
+	case FineTuneSucceeded, FineTuneFailed, FineTuneCancelled:
+		return true
+	}
+	return false
+}
+
+// FineTuneJobList represents the response of the list fine-tuning jobs
+// endpoint.
+type FineTuneJobList struct {
+	Object  string         `json:"object"`
+	Data    []*FineTuneJob `json:"data"`
+	HasMore bool           `json:"has_more"`
+}
+
+// Create starts a job fine-tuning model on the uploaded file with ID
+// trainingFileID.
+func (s *FineTuningService) Create(ctx context.Context, trainingFileID, model string, options ...FineTuneOption) (*FineTuneJob, error) {
+	r := &FineTuneRequest{TrainingFile: trainingFileID, Model: model}
+	for _, option := range options {
+		option(r)
+	}
+
+	req, err := s.client.NewRequest("POST", "fine_tuning/jobs", r)
+	if err != nil {
+		return nil, err
+	}
+
+	j := new(FineTuneJob)
+	_, err = s.client.Do(ctx, req, j)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// List returns the first page of the organization's fine-tuning jobs,
+// most recent first. Use ListAll to fetch them all.
+func (s *FineTuningService) List(ctx context.Context) (*FineTuneJobList, error) {
+	req, err := s.client.NewRequest("GET", "fine_tuning/jobs", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(FineTuneJobList)
+	_, err = s.client.Do(ctx, req, l)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Retrieve returns the fine-tuning job with the given ID. Call it
+// periodically and check Done to follow a job's progress.
+func (s *FineTuningService) Retrieve(ctx context.Context, id string) (*FineTuneJob, error) {
+	u := fmt.Sprintf("fine_tuning/jobs/%v", url.PathEscape(id))
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	j := new(FineTuneJob)
+	_, err = s.client.Do(ctx, req, j)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Cancel cancels the fine-tuning job with the given ID.
+func (s *FineTuningService) Cancel(ctx context.Context, id string) (*FineTuneJob, error) {
+	u := fmt.Sprintf("fine_tuning/jobs/%v/cancel", url.PathEscape(id))
+	req, err := s.client.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	j := new(FineTuneJob)
+	_, err = s.client.Do(ctx, req, j)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
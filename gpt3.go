@@ -5,48 +5,245 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
+	// Version is the version of this package, sent in the User-Agent.
+	Version = "0.1.0"
+
 	defaultBaseURL = "https://api.openai.com/v1/"
-	userAgent      = "gpt3-go"
+	userAgent      = "gpt3-go/" + Version
+
+	// Long generations can take minutes, so the default timeout is generous.
+	defaultTimeout = 10 * time.Minute
+
+	defaultMaxResponseBytes = 32 << 20
 )
 
 // A Client manages communication with the OpenAI API.
+//
+// A Client is safe for concurrent use by multiple goroutines, so a single
+// client can serve a whole worker pool. Requests only read its fields, which
+// must therefore not be changed once the client is in use; build a new
+// client instead. The hooks it calls, such as Logger, Tracer, Metrics,
+// Cache and OnRetry, are shared by all requests and must themselves be safe
+// for concurrent use.
 type Client struct {
 	// HTTP client used to communicate with the API.
 	client *http.Client
 
-	// Base URL for API requests.
+	// Base URL for API requests. Defaults to the public OpenAI API, but can
+	// point at a proxy, gateway or test server. A missing trailing slash is
+	// tolerated.
 	BaseURL *url.URL
 
-	// User agent used when communicating with the OpenAI API.
+	// User agent used when communicating with the OpenAI API. Defaults to
+	// "gpt3-go/" followed by Version.
 	UserAgent string
 
 	// API key used when communicating with the OpenAI API.
 	APIKey string
 
+	// Organization, if set, is sent in the OpenAI-Organization header so
+	// that usage is billed to that organization.
+	Organization string
+
+	// MaxRetries is the number of times a request is retried after a
+	// transient failure: a transport error or a 429, 500, 502, 503 or 504
+	// response. Zero disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the base delay before the first retry. It doubles on
+	// every subsequent attempt and is randomized with jitter. A Retry-After
+	// header on the response takes precedence.
+	RetryBackoff time.Duration
+
+	// RateLimiter, if non-nil, delays completion and chat requests so they
+	// stay within its request and token budgets.
+	RateLimiter *RateLimiter
+
+	// Tracer, if non-nil, records a span around every completion and chat
+	// call.
+	Tracer Tracer
+
+	// Metrics, if non-nil, is told the outcome of every completion and
+	// chat call.
+	Metrics Metrics
+
+	// Cache, if non-nil, serves repeated completion requests without
+	// calling the API. Only requests accepted by CachePolicy are cached.
+	// Cached Completions are shared between callers and must not be
+	// modified. Streaming requests are never cached. Entries are keyed by
+	// the request body together with the base URL, organization, API key
+	// and per-request headers it is sent with, so a request made with
+	// WithRequestAPIKey or WithHeader is only answered from responses
+	// obtained with the same credentials and headers.
+	Cache Cache
+
+	// CachePolicy decides which completion requests are cached. If nil,
+	// DefaultCachePolicy is used.
+	CachePolicy func(r *CompletionRequest) bool
+
+	// ShouldRetry, if non-nil, decides whether a failed attempt is retried,
+	// given its response, which is nil if none was received, its error and
+	// the number the retry would have, starting at 1. It replaces
+	// DefaultShouldRetry; MaxRetries and the backoff still apply. It is
+	// only consulted for failed attempts, including error responses, which
+	// come with an *APIError: successful attempts and requests whose
+	// context is done are never retried.
+	ShouldRetry func(resp *http.Response, err error, attempt int) bool
+
+	// MaxResponseBytes limits the size of the response bodies the client
+	// reads into memory: decoded JSON responses and error responses. A
+	// larger body makes the call fail with ErrResponseTooLarge. Bodies
+	// copied to an io.Writer and streamed responses are not limited, as
+	// they are not buffered. It defaults to 32 MiB; zero or less disables
+	// the limit.
+	MaxResponseBytes int64
+
+	// Clock used for retry backoff and latency measurements, set by
+	// WithClock. Nil means the real clock.
+	nowFunc   func() time.Time
+	sleepFunc func(time.Duration)
+
+	// OnRetry, if non-nil, is called before the client waits to retry a
+	// request, with the number of the retry starting at 1, the error of
+	// the failed attempt and the delay before the next one. A spike in
+	// retries usually means the API is degraded.
+	OnRetry func(attempt int, err error, delay time.Duration)
+
+	// LogUsage makes the client write a line to Logger after every
+	// successful completion and chat call, reporting the model, token
+	// usage, latency and finish reason as key=value pairs with stable
+	// keys, for example:
+	//
+	//	gpt3: usage model=gpt-4o prompt_tokens=12 completion_tokens=40 total_tokens=52 latency_ms=830 finish_reason=stop
+	LogUsage bool
+
+	// Model is the model used by completion, chat and edit requests that
+	// do not set one with the Model option.
+	Model string
+
+	// DefaultOptions are applied to every completion, chat and edit
+	// request before the options passed to the call, which can override
+	// them.
+	DefaultOptions []Option
+
+	// RequestMiddleware, if non-nil, is called with every request before it
+	// is first sent, with the request context attached. It may add headers
+	// such as correlation IDs; an error aborts the request.
+	RequestMiddleware func(*http.Request) error
+
+	// SignRequest, if non-nil, is called before every attempt to send a
+	// request, retries included, with the request and its body exactly as
+	// it will be sent, after compression. It can add headers computed over
+	// the body, such as an HMAC signature required by a gateway; an error
+	// aborts the request. Unlike RequestMiddleware it runs again for each
+	// retry, so time-based signatures stay fresh.
+	SignRequest func(req *http.Request, body []byte) error
+
+	// CompressRequests, when set, gzips JSON request bodies of 1 KiB or
+	// more and marks them with Content-Encoding: gzip. Only enable it for
+	// servers or gateways that accept compressed requests. Responses are
+	// always accepted gzipped and decompressed transparently.
+	CompressRequests bool
+
+	// Debug, when set, additionally logs the full request and response
+	// bodies to Logger. Bodies may contain sensitive prompts and output,
+	// so enable it only while debugging.
+	Debug bool
+
+	// Logger, if non-nil, receives a line for every request sent and every
+	// response received. API keys are redacted from logged headers.
+	Logger Logger
+
+	// azure is set for clients created by NewAzureClient.
+	azure *AzureConfig
+
 	// Services used for communicating with the API
 	Completions *CompletionsService
+	Chat        *ChatService
+	Embeddings  *EmbeddingsService
+	Moderations *ModerationsService
+	Models      *ModelsService
+	Images      *ImagesService
+	Audio       *AudioService
+	Edits       *EditsService
+	Files       *FilesService
+	FineTuning  *FineTuningService
+	Batches     *BatchesService
 }
 
-// NewClient returns a new OpenAI API client.
+// NewClient returns a new OpenAI API client with default settings. Use
+// NewClientWithOptions to customize the underlying HTTP client.
 func NewClient(apiKey string) *Client {
+	return NewClientWithOptions(apiKey)
+}
+
+// NewClientWithOptions returns a new OpenAI API client configured by opts.
+// Unless overridden, requests time out after 10 minutes and are sent with
+// the pooled transport returned by NewTransport.
+func NewClientWithOptions(apiKey string, opts ...ClientOption) *Client {
 	baseURL, _ := url.Parse(defaultBaseURL)
 
-	c := &Client{client: http.DefaultClient, BaseURL: baseURL, UserAgent: userAgent, APIKey: apiKey}
+	c := &Client{client: &http.Client{Timeout: defaultTimeout}, BaseURL: baseURL, UserAgent: userAgent, APIKey: apiKey, RetryBackoff: defaultRetryBackoff, MaxResponseBytes: defaultMaxResponseBytes}
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.httpClient == nil && o.transport == nil {
+		c.client.Transport = NewTransport()
+	}
+	o.apply(c)
+
 	c.Completions = &CompletionsService{client: c}
+	c.Chat = &ChatService{client: c}
+	c.Embeddings = &EmbeddingsService{client: c}
+	c.Moderations = &ModerationsService{client: c}
+	c.Models = &ModelsService{client: c}
+	c.Images = &ImagesService{client: c}
+	c.Audio = &AudioService{client: c}
+	c.Edits = &EditsService{client: c}
+	c.Files = &FilesService{client: c}
+	c.FineTuning = &FineTuningService{client: c}
+	c.Batches = &BatchesService{client: c}
 	return c
 }
 
+// NewClientFromEnv returns a new OpenAI API client configured from the
+// environment. The API key is read from OPENAI_API_KEY, which must be set.
+// OPENAI_ORG_ID and OPENAI_BASE_URL, if set, provide the organization and
+// base URL. Options in opts take precedence over the environment.
+func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
+	apiKey := Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("gpt3: OPENAI_API_KEY is not set")
+	}
+
+	var envOpts []ClientOption
+	if org := Getenv("OPENAI_ORG_ID"); org != "" {
+		envOpts = append(envOpts, WithOrganization(org))
+	}
+	if baseURL := Getenv("OPENAI_BASE_URL"); baseURL != "" {
+		if _, err := url.Parse(baseURL); err != nil {
+			return nil, fmt.Errorf("gpt3: invalid OPENAI_BASE_URL: %v", err)
+		}
+		envOpts = append(envOpts, WithBaseURL(baseURL))
+	}
+	return NewClientWithOptions(apiKey, append(envOpts, opts...)...), nil
+}
+
 // NewRequest creates an API request. A relative URL can be provided in urlStr,
 // in which case it is resolved relative to the BaseURL of the Client.
 // Relative URLs should always be specified without a preceding slash. If
@@ -58,15 +255,37 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 		return nil, err
 	}
 
-	u := c.BaseURL.ResolveReference(rel)
+	// Without a trailing slash the last path segment of the base URL would
+	// be replaced rather than extended.
+	base := *c.BaseURL
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+		if base.RawPath != "" {
+			base.RawPath += "/"
+		}
+	}
+	u := base.ResolveReference(rel)
+	if c.azure != nil {
+		q := u.Query()
+		q.Set("api-version", c.azure.APIVersion)
+		u.RawQuery = q.Encode()
+	}
 
 	var buf io.ReadWriter
+	compressed := false
 	if body != nil {
-		buf = new(bytes.Buffer)
-		err := json.NewEncoder(buf).Encode(body)
+		b := new(bytes.Buffer)
+		err := json.NewEncoder(b).Encode(body)
 		if err != nil {
 			return nil, err
 		}
+		if c.CompressRequests && b.Len() >= minCompressSize {
+			if b, err = gzipBuffer(b); err != nil {
+				return nil, err
+			}
+			compressed = true
+		}
+		buf = b
 	}
 
 	req, err := http.NewRequest(method, u.String(), buf)
@@ -75,21 +294,219 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 	}
 
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	req.Header.Add("Accept-Encoding", "gzip")
+	if compressed {
+		req.Header.Add("Content-Encoding", "gzip")
+	}
+	c.setAPIKey(req, c.APIKey)
 	req.Header.Add("User-Agent", c.UserAgent)
+	if c.Organization != "" {
+		req.Header.Add("OpenAI-Organization", c.Organization)
+	}
 	return req, nil
 }
 
-// Do sends an API request and returns the API response. The API response is
-// JSON decoded and stored in the value pointed to by v, or returned as an
-// error if an API error has occurred. If v implements the io.Writer
-// interface, the raw response body will be written to v, without attempting to
-// first decode it.
-func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+// NewUploadRequest creates an API request with a raw body, such as a
+// multipart form. A relative URL is resolved as in NewRequest. The body can
+// only be resent on retry if it is a *bytes.Buffer, *bytes.Reader or
+// *strings.Reader.
+func (c *Client) NewUploadRequest(method, urlStr string, body io.Reader, mediaType string) (*http.Request, error) {
+	req, err := c.NewRequest(method, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := http.NewRequest(method, req.URL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	upload.Header = req.Header
+	upload.Header.Set("Content-Type", mediaType)
+	return upload, nil
+}
+
+// newParamsRequest creates an API request as NewRequest does, then applies
+// the extra body fields, per-request API key and header overrides held by
+// p.
+func (c *Client) newParamsRequest(method, urlStr string, body interface{}, p *Params) (*http.Request, error) {
+	body, err := withExtra(body, p.extra)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.NewRequest(method, urlStr, body)
+	if err != nil {
+		return nil, err
+	}
+	if p.apiKey != "" {
+		c.setAPIKey(req, p.apiKey)
+	}
+	for name, values := range p.header {
+		if name == "User-Agent" {
+			// Keep the client's product token so requests remain
+			// attributable to this library.
+			values = []string{strings.Join(append(values[:len(values):len(values)], c.UserAgent), " ")}
+		}
+		req.Header[name] = values
+	}
+	return req, nil
+}
+
+// applyOptions sets the parameters of p from the client's Model and
+// DefaultOptions followed by options.
+func (c *Client) applyOptions(p *Params, options []Option) {
+	p.Model = c.Model
+	for _, option := range c.DefaultOptions {
+		option(p)
+	}
+	for _, option := range options {
+		option(p)
+	}
+}
+
+// cachePolicy returns the policy deciding which requests are cached.
+func (c *Client) cachePolicy() func(r *CompletionRequest) bool {
+	if c.CachePolicy != nil {
+		return c.CachePolicy
+	}
+	return DefaultCachePolicy
+}
+
+// waitRateLimit blocks until the client's RateLimiter admits a request
+// of the given estimated size.
+func (c *Client) waitRateLimit(ctx context.Context, tokens int) error {
+	if c.RateLimiter == nil {
+		return nil
+	}
+	return c.RateLimiter.Wait(ctx, tokens)
+}
+
+// setAPIKey sets the header that authenticates req with apiKey.
+func (c *Client) setAPIKey(req *http.Request, apiKey string) {
+	if c.azure != nil {
+		req.Header.Set("api-key", apiKey)
+	} else {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	}
+}
+
+// Ping checks that the API is reachable and accepts the client's
+// credentials by listing the available models, which costs no tokens. It
+// returns nil on success and otherwise the error of the request, an
+// *APIError if the API answered.
+//
+// For clients created by NewAzureClient, the models are listed from the
+// resource, {endpoint}/openai/models, as deployments do not serve them.
+func (c *Client) Ping(ctx context.Context) error {
+	path := "models"
+	if c.azure != nil {
+		path = "../../models"
+	}
+	req, err := c.NewRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.Do(ctx, req, ioutil.Discard)
+	return err
+}
+
+// BareDo sends an API request and returns the API response without reading
+// its body; the caller must close it. Error responses are returned as an
+// *APIError together with the response, whose body has then already been
+// consumed and closed.
+//
+// Transient failures are retried up to MaxRetries times with exponential
+// backoff. Retrying stops early if ctx is done or its deadline would pass
+// before the next attempt. Every attempt carries the same Idempotency-Key
+// header, so that the server can recognize retries; when retries are
+// enabled and req has no key, a random one is generated for POST requests.
+func (c *Client) BareDo(ctx context.Context, req *http.Request) (*http.Response, error) {
 	req = req.WithContext(ctx)
+	if c.MaxRetries > 0 && req.Method == "POST" && req.Header.Get(idempotencyKeyHeader) == "" {
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return nil, err
+		}
+		req.Header = req.Header.Clone()
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	if c.RequestMiddleware != nil {
+		if err := c.RequestMiddleware(req); err != nil {
+			return nil, err
+		}
+	}
 
+	for attempt := 0; ; attempt++ {
+		if c.SignRequest != nil {
+			if err := c.signRequest(req); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := c.send(ctx, req)
+		if attempt >= c.MaxRetries || !c.shouldRetry(ctx, resp, err, attempt+1) {
+			return resp, err
+		}
+
+		delay := c.retryDelay(attempt, resp)
+		// Context deadlines are wall-clock times, so they are checked
+		// against the real clock even when WithClock replaced it.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+		if rewindBody(req) != nil {
+			return resp, err
+		}
+
+		c.logf("gpt3: %s %s: retrying in %v (attempt %d of %d)", req.Method, sanitizeURL(req.URL), delay, attempt+1, c.MaxRetries)
+		countRetry(ctx)
+		if c.OnRetry != nil {
+			c.OnRetry(attempt+1, err, delay)
+		}
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// signRequest passes req and its body to SignRequest. A body that cannot
+// be read again is buffered so that it can still be sent.
+func (c *Client) signRequest(req *http.Request) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if req.GetBody != nil {
+			var r io.ReadCloser
+			if r, err = req.GetBody(); err != nil {
+				return err
+			}
+			body, err = ioutil.ReadAll(r)
+			r.Close()
+		} else {
+			body, err = ioutil.ReadAll(req.Body)
+			req.Body.Close()
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			req.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader(body)), nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return c.SignRequest(req, body)
+}
+
+// send sends req once and checks the response for errors, as described in
+// BareDo.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	c.logf("gpt3: %s %s %v", req.Method, sanitizeURL(req.URL), redactHeader(req.Header))
+	if c.Debug {
+		c.dumpRequest(req)
+	}
+	start := c.now()
 	resp, err := c.client.Do(req)
 	if err != nil {
+		c.logf("gpt3: %s %s: %v (%v)", req.Method, sanitizeURL(req.URL), err, c.now().Sub(start))
+
 		// If we got an error, and the context has been canceled,
 		// the context's error is probably more useful.
 		select {
@@ -108,56 +525,310 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*htt
 
 		return nil, err
 	}
-	defer resp.Body.Close()
+	c.logf("gpt3: %s %s: %d (%v)", req.Method, sanitizeURL(req.URL), resp.StatusCode, c.now().Sub(start))
+	decompressResponse(resp)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{c.limitBody(resp.Body), resp.Body}
+	}
+	if c.Debug {
+		c.dumpResponse(resp)
+	}
 
 	err = CheckResponse(resp)
 	if err != nil {
 		// even though there was an error, we still return the response
 		// in case the caller wants to inspect it further
+		resp.Body.Close()
 		return resp, err
 	}
+	return resp, nil
+}
+
+// Do sends an API request and returns the API response. The API response is
+// JSON decoded and stored in the value pointed to by v, or returned as an
+// error if an API error has occurred. If v implements the io.Writer
+// interface, the raw response body will be written to v, without attempting to
+// first decode it.
+//
+// Transient failures are retried as described in BareDo.
+func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	resp, err := c.BareDo(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	defer resp.Body.Close()
 
 	if v != nil {
 		if w, ok := v.(io.Writer); ok {
-			io.Copy(w, resp.Body)
+			_, err = io.Copy(w, resp.Body)
 		} else {
-			err = json.NewDecoder(resp.Body).Decode(v)
+			err = json.NewDecoder(c.limitBody(resp.Body)).Decode(v)
 			if err == io.EOF {
 				err = nil // ignore EOF errors caused by empty response body
 			}
 		}
 	}
 
+	// A context canceled while the body was being read surfaces as a
+	// decoding error; report the context's error instead.
+	if err != nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	if r, ok := v.(responseRecorder); ok && err == nil {
+		r.recordResponse(resp)
+	}
+
 	return resp, err
 }
 
-// An ErrorResponse reports one or more errors caused by an API request.
-type ErrorResponse struct {
-	Response *http.Response // HTTP response that caused this error
-	Message  string         `json:"message"` // error message
+// A responseRecorder is a response type that keeps metadata from the
+// headers of the HTTP response it was decoded from.
+type responseRecorder interface {
+	recordResponse(resp *http.Response)
+}
+
+// unixTime converts a timestamp in seconds since the Unix epoch, as the API
+// reports them, to a time.Time. A zero timestamp, meaning the API did not
+// report one, yields the zero time.
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// ErrResponseTooLarge is returned when a response body exceeds the client's
+// MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("gpt3: response body exceeds MaxResponseBytes")
+
+// limitBody returns r limited to the client's MaxResponseBytes.
+func (c *Client) limitBody(r io.Reader) io.Reader {
+	if c.MaxResponseBytes <= 0 {
+		return r
+	}
+	return &limitedReader{r: r, n: c.MaxResponseBytes}
 }
 
-func (r *ErrorResponse) Error() string {
+// limitedReader reads from r until more than n bytes have been read, then
+// fails with ErrResponseTooLarge.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, ErrResponseTooLarge
+	}
+	return n, err
+}
+
+// An APIError reports an error returned by the OpenAI API. Callers can use
+// errors.As to inspect the StatusCode, Type and Code of a failed request.
+type APIError struct {
+	Response   *http.Response `json:"-"`       // HTTP response that caused this error
+	StatusCode int            `json:"-"`       // HTTP status code of the response
+	Message    string         `json:"message"` // error message
+	Type       string         `json:"type"`    // error type, e.g. "invalid_request_error"
+	Code       string         `json:"code"`    // error code, e.g. "invalid_api_key"
+	RequestID  string         `json:"-"`       // x-request-id header, for support tickets
+}
+
+func (r *APIError) Error() string {
+	msg := r.Message
+	if r.RequestID != "" {
+		msg = fmt.Sprintf("%v (request ID %v)", msg, r.RequestID)
+	}
+	if r.Response == nil || r.Response.Request == nil {
+		return fmt.Sprintf("%d %v", r.StatusCode, msg)
+	}
 	return fmt.Sprintf("%v %v: %d %v",
 		r.Response.Request.Method, sanitizeURL(r.Response.Request.URL),
-		r.Response.StatusCode, r.Message)
+		r.StatusCode, msg)
 }
 
+// An ErrorResponse reports one or more errors caused by an API request.
+//
+// Deprecated: Use APIError.
+type ErrorResponse = APIError
+
 // CheckResponse checks the API response for errors, and returns them if
 // present. A response is considered an error if it has a status code outside
-// the 200 range. API error responses are expected to have either no response
-// body, or a JSON response body that maps to ErrorResponse. Any other
-// response body will be silently ignored.
+// the 200 range. The returned error is an *APIError populated from the
+// {"error": {...}} envelope of the response body; if the body does not have
+// that shape, its raw text is used as the message. An error with the code
+// context_length_exceeded is returned as a *ContextLengthError instead.
 func CheckResponse(r *http.Response) error {
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return nil
 	}
-	errorResponse := &ErrorResponse{Response: r}
+	apiError := &APIError{Response: r, StatusCode: r.StatusCode, RequestID: r.Header.Get("x-request-id")}
 	data, err := ioutil.ReadAll(r.Body)
-	if err == nil && data != nil {
-		json.Unmarshal(data, errorResponse)
+	if err != nil {
+		// A body cut off by MaxResponseBytes cannot be parsed; say why
+		// rather than leave the message empty.
+		apiError.Message = fmt.Sprintf("reading error body: %v", err)
+	} else if len(data) > 0 {
+		envelope := struct {
+			Error *APIError `json:"error"`
+		}{Error: apiError}
+		if json.Unmarshal(data, &envelope) != nil || apiError.Message == "" {
+			apiError.Message = strings.TrimSpace(string(data))
+		}
+	}
+	if apiError.Code == "context_length_exceeded" {
+		return newContextLengthError(apiError)
+	}
+	return apiError
+}
+
+// A ContextLengthError reports that the prompt and the requested completion
+// do not fit in the model's context window. MaxTokens is the size of the
+// window and RequestedTokens the number of tokens asked for; either is zero
+// if it could not be parsed from the message. Shortening the prompt, for
+// example with TruncateToFit, or lowering max_tokens may help.
+//
+// errors.As also finds the underlying *APIError.
+type ContextLengthError struct {
+	*APIError
+	MaxTokens       int
+	RequestedTokens int
+}
+
+// Unwrap returns the underlying *APIError.
+func (e *ContextLengthError) Unwrap() error {
+	return e.APIError
+}
+
+var (
+	maxContextRE       = regexp.MustCompile(`maximum context length is (\d+) tokens`)
+	requestedContextRE = regexp.MustCompile(`(?:requested|resulted in) (\d+) tokens`)
+)
+
+func newContextLengthError(apiError *APIError) *ContextLengthError {
+	e := &ContextLengthError{APIError: apiError}
+	if m := maxContextRE.FindStringSubmatch(apiError.Message); m != nil {
+		e.MaxTokens, _ = strconv.Atoi(m[1])
+	}
+	if m := requestedContextRE.FindStringSubmatch(apiError.Message); m != nil {
+		e.RequestedTokens, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
+
+// A Logger receives diagnostic output from a Client. It is called from
+// every goroutine using the client, so it must be safe for concurrent use.
+// *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// logf writes a line to the client's Logger, if one is set.
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, v...)
+	}
+}
+
+// logOptions logs the parameters of a request body, leaving out the prompt
+// and messages so user content does not end up in the logs.
+func (c *Client) logOptions(body interface{}) {
+	if c.Logger == nil {
+		return
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return
+	}
+	delete(fields, "prompt")
+	delete(fields, "messages")
+	data, _ = json.Marshal(fields)
+	c.logf("gpt3: options %s", data)
+}
+
+// dumpRequest logs the body of req without consuming it.
+func (c *Client) dumpRequest(req *http.Request) {
+	if c.Logger == nil || req.GetBody == nil {
+		return
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return
+	}
+	c.logf("gpt3: request body:\n%s", formatBody(data, req.Header.Get("Content-Type")))
+}
+
+// dumpResponse logs the body of resp and replaces it with an unread copy.
+// Streaming bodies are not read, since that would defeat streaming.
+func (c *Client) dumpResponse(resp *http.Response) {
+	if c.Logger == nil {
+		return
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		c.logf("gpt3: response body: (event stream not logged)")
+		return
+	}
+	// Read no more than the caller would, and leave whatever is unread in
+	// place, so that a body over MaxResponseBytes still fails the call with
+	// ErrResponseTooLarge.
+	body := resp.Body
+	data, err := ioutil.ReadAll(c.limitBody(body))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), body), body}
+	if err != nil {
+		c.logf("gpt3: response body: %v", err)
+		return
+	}
+	c.logf("gpt3: response body:\n%s", formatBody(data, contentType))
+}
+
+// formatBody returns a body for logging: JSON is indented, other text is
+// kept as is, and binary content is summarized.
+func formatBody(data []byte, contentType string) string {
+	var out bytes.Buffer
+	if json.Indent(&out, data, "", "  ") == nil {
+		return out.String()
+	}
+	if strings.HasPrefix(contentType, "text/") {
+		return string(data)
+	}
+	return fmt.Sprintf("(%d bytes of %s)", len(data), contentType)
+}
+
+// redactHeader returns a copy of h with credentials masked, suitable for
+// logging.
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, key := range []string{"Authorization", "api-key"} {
+		if h.Get(key) != "" {
+			h.Set(key, "REDACTED")
+		}
 	}
-	return errorResponse
+	return h
 }
 
 // sanitizeURL redacts the client_secret parameter from the URL which may be
@@ -0,0 +1,151 @@
+package gpt3
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestClientConcurrentUse shares one client between many goroutines issuing
+// completion and chat calls. Run it with -race to check that requests do
+// not race on the client's state.
+func TestClientConcurrentUse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/chat/completions") {
+			fmt.Fprint(w, `{"id":"chat","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"total_tokens":2}}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"cmpl","choices":[{"index":1,"text":"b"},{"index":0,"text":"a","finish_reason":"stop"}],"usage":{"total_tokens":2}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClientWithOptions("key",
+		WithBaseURL(srv.URL+"/"),
+		WithModel("model"),
+		WithDefaultOptions(MaxTokens(16), Temperature(0)),
+		WithCache(NewLRUCache(16)),
+		WithLogger(log.New(ioutil.Discard, "", 0)),
+		WithUsageLogging(),
+	)
+
+	const workers = 64
+	ctx := context.Background()
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			switch i % 3 {
+			case 0:
+				_, err = client.Completions.CompleteContext(ctx, fmt.Sprintf("prompt %d", i%4))
+			case 1:
+				_, err = client.Completions.CompleteBatch(ctx, []string{"a", "b"}, Temperature(0.5))
+			case 2:
+				_, err = client.Chat.CompleteContext(ctx, NewMessages("system", "hello"), MaxTokens(8))
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// TestShouldRetryIgnoresSuccess checks that a permissive retry policy does
+// not make the client send a successful request again.
+func TestShouldRetryIgnoresSuccess(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		fmt.Fprint(w, `{"id":"cmpl","choices":[{"index":0,"text":"a"}]}`)
+	}))
+	defer srv.Close()
+
+	client := NewClientWithOptions("key",
+		WithBaseURL(srv.URL+"/"),
+		WithModel("model"),
+		WithShouldRetry(func(resp *http.Response, err error, attempt int) bool { return true }),
+	)
+	client.MaxRetries = 3
+
+	c, err := client.Completions.CompleteContext(context.Background(), "prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("server hit %d times, want 1", n)
+	}
+	if c.Retries != 0 {
+		t.Errorf("Retries = %d, want 0", c.Retries)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// TestNewClientReplacedDefaultTransport checks that clients can be created
+// when http.DefaultTransport is not an *http.Transport, as set up by some
+// instrumentation and mocking libraries.
+func TestNewClientReplacedDefaultTransport(t *testing.T) {
+	saved := http.DefaultTransport
+	defer func() { http.DefaultTransport = saved }()
+	http.DefaultTransport = roundTripperFunc(saved.RoundTrip)
+
+	if NewTransport().Proxy == nil {
+		t.Error("NewTransport ignores proxy settings from the environment")
+	}
+	NewClient("key")
+	NewClientWithOptions("key", WithTransport(http.DefaultTransport))
+}
+
+// TestChatCompleteStreamNilFn checks that CompleteStream accepts a nil fn
+// and still assembles the response.
+func TestChatCompleteStreamNilFn(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"id\":\"chat\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"hi\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"id\":\"chat\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	client := NewClientWithOptions("key", WithBaseURL(srv.URL+"/"), WithModel("model"))
+	c, err := client.Chat.CompleteStream(context.Background(), NewMessages("system", "hello"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.String(); got != "hi" {
+		t.Errorf("content = %q, want %q", got, "hi")
+	}
+}
+
+// TestChatCompleteStreamBadToolCallIndex checks that a stream with an
+// out-of-range tool call index fails instead of panicking.
+func TestChatCompleteStreamBadToolCallIndex(t *testing.T) {
+	for _, index := range []int{-1, 1 << 30} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "data: {\"id\":\"chat\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":%d,\"function\":{\"arguments\":\"{}\"}}]}}]}\n\n", index)
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		client := NewClientWithOptions("key", WithBaseURL(srv.URL+"/"), WithModel("model"))
+		_, err := client.Chat.CompleteStream(context.Background(), NewMessages("system", "hello"), nil)
+		srv.Close()
+		if err == nil {
+			t.Errorf("index %d: got no error", index)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package gpt3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// minCompressSize is the smallest request body CompressRequests compresses;
+// below it the gzip overhead outweighs the savings.
+const minCompressSize = 1024
+
+// gzipBuffer returns the gzip-compressed contents of b.
+func gzipBuffer(b *bytes.Buffer) (*bytes.Buffer, error) {
+	out := new(bytes.Buffer)
+	zw := gzip.NewWriter(out)
+	if _, err := b.WriteTo(zw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// decompressResponse replaces a gzip-encoded response body with its
+// decompressed contents. The transport only does this itself when it
+// added the Accept-Encoding header, which NewRequest sets explicitly.
+func decompressResponse(resp *http.Response) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// gzipBody decompresses a response body on first read, so that empty
+// bodies do not cause an error until they are actually read.
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+func (g *gzipBody) Read(p []byte) (int, error) {
+	if g.zr == nil && g.err == nil {
+		g.zr, g.err = gzip.NewReader(g.body)
+	}
+	if g.err != nil {
+		return 0, g.err
+	}
+	return g.zr.Read(p)
+}
+
+func (g *gzipBody) Close() error {
+	return g.body.Close()
+}
@@ -0,0 +1,115 @@
+package gpt3
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// ImagesService handles communication with the image related methods of
+// the OpenAI API.
+type ImagesService struct {
+	client *Client
+}
+
+// ImageRequest represents the JSON body sent to the image generation
+// endpoint. Fields left unset are omitted and take the API defaults.
+type ImageRequest struct {
+	Prompt         string `json:"prompt"`
+	Model          string `json:"model,omitempty"`
+	N              *int   `json:"n,omitempty"`
+	Size           string `json:"size,omitempty"`
+	Quality        string `json:"quality,omitempty"`
+	ResponseFormat string `json:"response_format,omitempty"`
+	User           string `json:"user,omitempty"`
+}
+
+// An ImageOption sets a parameter on an ImageRequest.
+type ImageOption func(*ImageRequest)
+
+// ImageModel sets the model used to generate images, such as "dall-e-3".
+func ImageModel(model string) ImageOption {
+	return func(r *ImageRequest) { r.Model = model }
+}
+
+// ImageCount sets the number of images to generate. dall-e-3 only supports
+// one.
+func ImageCount(n int) ImageOption {
+	return func(r *ImageRequest) { r.N = Int(n) }
+}
+
+// ImageSize sets the size of the generated images, such as "1024x1024".
+func ImageSize(size string) ImageOption {
+	return func(r *ImageRequest) { r.Size = size }
+}
+
+// ImageQuality sets the quality of the generated images, such as "hd".
+func ImageQuality(quality string) ImageOption {
+	return func(r *ImageRequest) { r.Quality = quality }
+}
+
+// ImageResponseFormat sets how images are returned: "url" for links that
+// expire after an hour, or "b64_json" for the encoded image data.
+func ImageResponseFormat(format string) ImageOption {
+	return func(r *ImageRequest) { r.ResponseFormat = format }
+}
+
+// ImageResponse represents the response of the image generation endpoint.
+type ImageResponse struct {
+	Created int64    `json:"created"`
+	Data    []*Image `json:"data"`
+}
+
+// CreatedTime returns the time the images were created.
+func (r *ImageResponse) CreatedTime() time.Time {
+	return unixTime(r.Created)
+}
+
+// URLs returns the URLs of the generated images. It is empty when images
+// were requested as b64_json.
+func (r *ImageResponse) URLs() []string {
+	var urls []string
+	for _, img := range r.Data {
+		if img.URL != "" {
+			urls = append(urls, img.URL)
+		}
+	}
+	return urls
+}
+
+// Image is a single generated image. Either URL or B64JSON is set,
+// depending on the requested response format.
+type Image struct {
+	URL           string `json:"url,omitempty"`
+	B64JSON       string `json:"b64_json,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+}
+
+// Bytes returns the decoded image data of an image returned as b64_json.
+func (i *Image) Bytes() ([]byte, error) {
+	if i.B64JSON == "" {
+		return nil, errors.New("gpt3: image has no inline data; request the b64_json response format")
+	}
+	return base64.StdEncoding.DecodeString(i.B64JSON)
+}
+
+// Generate creates images from a text prompt.
+func (s *ImagesService) Generate(ctx context.Context, prompt string, options ...ImageOption) (*ImageResponse, error) {
+	r := &ImageRequest{Prompt: prompt}
+	for _, option := range options {
+		option(r)
+	}
+
+	req, err := s.client.NewRequest("POST", "images/generations", r)
+	if err != nil {
+		return nil, err
+	}
+
+	i := new(ImageResponse)
+	_, err = s.client.Do(ctx, req, i)
+	if err != nil {
+		return nil, err
+	}
+	return i, nil
+}
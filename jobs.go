@@ -0,0 +1,103 @@
+package gpt3
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// A JobError reports that a fine-tuning job or batch waited on with
+// FineTuningService.Wait or BatchesService.Wait ended without succeeding.
+// Cancellation of the wait itself is reported with the context's error
+// instead, so the two can be told apart.
+type JobError struct {
+	ID      string // ID of the job or batch
+	Status  string // final status, e.g. "failed" or "cancelled"
+	Message string // reason given by the API, if any
+}
+
+func (e *JobError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("gpt3: job %v ended with status %v: %v", e.ID, e.Status, e.Message)
+	}
+	return fmt.Sprintf("gpt3: job %v ended with status %v", e.ID, e.Status)
+}
+
+// Wait polls the fine-tuning job with the given ID every interval until it
+// finishes, and returns it. A job that fails or is cancelled is returned
+// along with a *JobError.
+//
+// The interval must be positive. If ctx is done first, Wait stops and
+// returns the job as last seen, which is nil if it was never retrieved,
+// with ctx.Err(). Errors retrieving the job are returned the same way.
+// Wait normally stops at once, but with a clock set by WithClock it finishes
+// the pause in progress first.
+func (s *FineTuningService) Wait(ctx context.Context, id string, interval time.Duration) (*FineTuneJob, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("gpt3: wait interval must be positive, got %v", interval)
+	}
+	var last *FineTuneJob
+	for {
+		j, err := s.Retrieve(ctx, id)
+		if err != nil {
+			return last, err
+		}
+		last = j
+		if j.Done() {
+			if j.Status != FineTuneSucceeded {
+				e := &JobError{ID: j.ID, Status: j.Status}
+				if j.Error != nil {
+					e.Message = j.Error.Message
+				}
+				return j, e
+			}
+			return j, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return last, err
+		}
+		if err := s.client.sleep(ctx, interval); err != nil {
+			return last, err
+		}
+	}
+}
+
+// Wait polls the batch with the given ID every interval until it reaches a
+// final status, and returns it. A batch that fails, expires or is cancelled
+// is returned along with a *JobError; a completed batch may still contain
+// failed requests, reported in its RequestCounts.
+//
+// The interval must be positive. If ctx is done first, Wait stops and
+// returns the batch as last seen, which is nil if it was never retrieved,
+// with ctx.Err(). Errors retrieving the batch are returned the same way.
+// Wait normally stops at once, but with a clock set by WithClock it finishes
+// the pause in progress first.
+func (s *BatchesService) Wait(ctx context.Context, id string, interval time.Duration) (*Batch, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("gpt3: wait interval must be positive, got %v", interval)
+	}
+	var last *Batch
+	for {
+		b, err := s.Retrieve(ctx, id)
+		if err != nil {
+			return last, err
+		}
+		last = b
+		if b.Done() {
+			if b.Status != BatchCompleted {
+				e := &JobError{ID: b.ID, Status: b.Status}
+				if b.Errors != nil && len(b.Errors.Data) > 0 {
+					e.Message = b.Errors.Data[0].Message
+				}
+				return b, e
+			}
+			return b, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return last, err
+		}
+		if err := s.client.sleep(ctx, interval); err != nil {
+			return last, err
+		}
+	}
+}
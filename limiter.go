@@ -0,0 +1,118 @@
+package gpt3
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A RateLimiter throttles requests on the client side so that a workload
+// stays within a requests-per-minute and tokens-per-minute budget. It is
+// safe for concurrent use.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+}
+
+// NewRateLimiter returns a limiter allowing requestsPerMinute requests and
+// tokensPerMinute tokens per minute. A limit of zero or less is not
+// enforced. Both budgets start full, so an initial burst up to the limits
+// is allowed.
+func NewRateLimiter(requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	now := time.Now()
+	return &RateLimiter{
+		requests: newBucket(requestsPerMinute, now),
+		tokens:   newBucket(tokensPerMinute, now),
+	}
+}
+
+// Wait blocks until a request consuming the given number of tokens may be
+// sent, or until ctx is done. If the wait would outlast the deadline of
+// ctx, Wait returns an error immediately instead of blocking.
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	l.mu.Lock()
+	now := time.Now()
+	delay := l.requests.reserve(1, now)
+	if d := l.tokens.reserve(tokens, now); d > delay {
+		delay = d
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		l.requests.cancel(1)
+		l.tokens.cancel(tokens)
+		l.mu.Unlock()
+		return fmt.Errorf("gpt3: rate limit wait of %v exceeds context deadline", delay)
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.requests.cancel(1)
+		l.tokens.cancel(tokens)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// bucket is a token bucket refilled continuously at perMinute per minute.
+// Its level may go negative, representing capacity reserved by waiters.
+type bucket struct {
+	capacity float64
+	level    float64
+	last     time.Time
+}
+
+// newBucket returns a full bucket, or nil if perMinute is not positive.
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{capacity: float64(perMinute), level: float64(perMinute), last: now}
+}
+
+// reserve takes n units from the bucket and returns how long to wait until
+// they are available.
+func (b *bucket) reserve(n int, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	rate := b.capacity / float64(time.Minute)
+	b.level += float64(now.Sub(b.last)) * rate
+	if b.level > b.capacity {
+		b.level = b.capacity
+	}
+	b.last = now
+
+	// A request larger than the whole budget could never be satisfied;
+	// let it through once the bucket is full.
+	need := float64(n)
+	if need > b.capacity {
+		need = b.capacity
+	}
+	b.level -= need
+	if b.level >= 0 {
+		return 0
+	}
+	return time.Duration(-b.level / rate)
+}
+
+// cancel returns n units reserved by an abandoned request.
+func (b *bucket) cancel(n int) {
+	if b == nil {
+		return
+	}
+	need := float64(n)
+	if need > b.capacity {
+		need = b.capacity
+	}
+	b.level += need
+}
@@ -0,0 +1,65 @@
+package gpt3
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ModelsService handles communication with the model related methods of the
+// OpenAI API.
+type ModelsService struct {
+	client *Client
+}
+
+// ModelInfo describes a model available through the API. It is not named
+// Model to avoid clashing with the Model option.
+type ModelInfo struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// CreatedTime returns the time the model was created.
+func (m *ModelInfo) CreatedTime() time.Time {
+	return unixTime(m.Created)
+}
+
+// ModelList represents the response of the list models endpoint.
+type ModelList struct {
+	Object string       `json:"object"`
+	Data   []*ModelInfo `json:"data"`
+}
+
+// List returns the models available to the API key.
+func (s *ModelsService) List(ctx context.Context) (*ModelList, error) {
+	req, err := s.client.NewRequest("GET", "models", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(ModelList)
+	_, err = s.client.Do(ctx, req, l)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Retrieve returns the model with the given ID.
+func (s *ModelsService) Retrieve(ctx context.Context, id string) (*ModelInfo, error) {
+	u := fmt.Sprintf("models/%v", url.PathEscape(id))
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(ModelInfo)
+	_, err = s.client.Do(ctx, req, m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
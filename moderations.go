@@ -0,0 +1,87 @@
+package gpt3
+
+import "context"
+
+// ModerationsService handles communication with the moderation related
+// methods of the OpenAI API.
+type ModerationsService struct {
+	client *Client
+}
+
+// ModerationRequest represents the JSON body sent to the moderations
+// endpoint.
+type ModerationRequest struct {
+	Input string `json:"input"`
+	Model string `json:"model,omitempty"`
+}
+
+// ModerationResponse represents the response of the moderations endpoint.
+type ModerationResponse struct {
+	ID      string              `json:"id"`
+	Model   string              `json:"model"`
+	Results []*ModerationResult `json:"results"`
+
+	// Flagged reports whether any of the results was flagged.
+	Flagged bool `json:"-"`
+}
+
+// ModerationResult is the classification of a single input.
+type ModerationResult struct {
+	Flagged        bool                     `json:"flagged"`
+	Categories     ModerationCategories     `json:"categories"`
+	CategoryScores ModerationCategoryScores `json:"category_scores"`
+}
+
+// ModerationCategories reports which content policy categories an input
+// violates.
+type ModerationCategories struct {
+	Harassment            bool `json:"harassment"`
+	HarassmentThreatening bool `json:"harassment/threatening"`
+	Hate                  bool `json:"hate"`
+	HateThreatening       bool `json:"hate/threatening"`
+	SelfHarm              bool `json:"self-harm"`
+	SelfHarmIntent        bool `json:"self-harm/intent"`
+	SelfHarmInstructions  bool `json:"self-harm/instructions"`
+	Sexual                bool `json:"sexual"`
+	SexualMinors          bool `json:"sexual/minors"`
+	Violence              bool `json:"violence"`
+	ViolenceGraphic       bool `json:"violence/graphic"`
+}
+
+// ModerationCategoryScores holds the model's confidence, between 0 and 1,
+// that an input violates each content policy category.
+type ModerationCategoryScores struct {
+	Harassment            float64 `json:"harassment"`
+	HarassmentThreatening float64 `json:"harassment/threatening"`
+	Hate                  float64 `json:"hate"`
+	HateThreatening       float64 `json:"hate/threatening"`
+	SelfHarm              float64 `json:"self-harm"`
+	SelfHarmIntent        float64 `json:"self-harm/intent"`
+	SelfHarmInstructions  float64 `json:"self-harm/instructions"`
+	Sexual                float64 `json:"sexual"`
+	SexualMinors          float64 `json:"sexual/minors"`
+	Violence              float64 `json:"violence"`
+	ViolenceGraphic       float64 `json:"violence/graphic"`
+}
+
+// Moderate classifies input against OpenAI's content policy. model selects
+// the moderation model, such as "omni-moderation-latest"; if empty, the
+// API default is used.
+func (s *ModerationsService) Moderate(ctx context.Context, input, model string) (*ModerationResponse, error) {
+	req, err := s.client.NewRequest("POST", "moderations", &ModerationRequest{Input: input, Model: model})
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(ModerationResponse)
+	_, err = s.client.Do(ctx, req, m)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range m.Results {
+		if r != nil && r.Flagged {
+			m.Flagged = true
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,432 @@
+package gpt3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Params holds the parameters shared by the request payloads of the
+// completion and chat endpoints. Options set these fields; fields left unset
+// are omitted from the request body. Suffix, Echo, BestOf and Logprobs are
+// only supported by the completions endpoint, and Tools and ToolChoice only
+// by the chat endpoint.
+type Params struct {
+	Model       string        `json:"model"`
+	Suffix      string        `json:"suffix,omitempty"`
+	MaxTokens   *int          `json:"max_tokens,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	TopP        *float64      `json:"top_p,omitempty"`
+	N           *int          `json:"n,omitempty"`
+	BestOf      *int          `json:"best_of,omitempty"`
+	Logprobs    *int          `json:"logprobs,omitempty"`
+	Stop        StopSequences `json:"stop,omitempty"`
+	Echo        bool          `json:"echo,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+
+	LogitBias map[int]float64 `json:"logit_bias,omitempty"`
+	User      string          `json:"user,omitempty"`
+	Seed      *int            `json:"seed,omitempty"`
+
+	ResponseFormat *ResponseFormatParam `json:"response_format,omitempty"`
+
+	Tools      []Tool      `json:"tools,omitempty"`
+	ToolChoice interface{} `json:"tool_choice,omitempty"`
+
+	// Per-request overrides, applied to the HTTP request rather than
+	// sent in the body.
+	apiKey       string
+	header       http.Header
+	includeUsage bool
+	idleTimeout  time.Duration
+	extra        map[string]interface{}
+	timeout      time.Duration
+
+	checkLogitBias bool
+}
+
+// ResponseFormatParam is the value of the response_format parameter.
+type ResponseFormatParam struct {
+	Type string `json:"type"`
+}
+
+// StreamOptions is the value of the stream_options parameter.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage,omitempty"`
+}
+
+// Validate checks p for values the API is known to reject, so that
+// mistakes are reported without a round trip to the server.
+func (p *Params) Validate() error {
+	if p.Model == "" {
+		return errors.New("gpt3: no model specified; use the Model option or WithModel")
+	}
+	if p.MaxTokens != nil && *p.MaxTokens <= 0 {
+		return fmt.Errorf("gpt3: max_tokens must be greater than 0, got %d", *p.MaxTokens)
+	}
+	if p.N != nil && *p.N < 1 {
+		return fmt.Errorf("gpt3: n must be at least 1, got %d", *p.N)
+	}
+	if err := checkRange("temperature", p.Temperature, 0, 2); err != nil {
+		return err
+	}
+	if err := checkRange("top_p", p.TopP, 0, 1); err != nil {
+		return err
+	}
+	if len(p.Stop) > maxStopSequences {
+		return fmt.Errorf("gpt3: at most %d stop sequences are allowed, got %d", maxStopSequences, len(p.Stop))
+	}
+	if p.BestOf != nil {
+		n := 1
+		if p.N != nil {
+			n = *p.N
+		}
+		if *p.BestOf < n {
+			return fmt.Errorf("gpt3: best_of (%d) must be greater than or equal to n (%d)", *p.BestOf, n)
+		}
+	}
+	if p.Logprobs != nil && (*p.Logprobs < 0 || *p.Logprobs > maxLogprobs) {
+		return fmt.Errorf("gpt3: logprobs must be between 0 and %d, got %d", maxLogprobs, *p.Logprobs)
+	}
+	if err := checkRange("presence_penalty", p.PresencePenalty, -2, 2); err != nil {
+		return err
+	}
+	if err := checkRange("frequency_penalty", p.FrequencyPenalty, -2, 2); err != nil {
+		return err
+	}
+	for token, bias := range p.LogitBias {
+		if bias < -100 || bias > 100 {
+			return fmt.Errorf("gpt3: logit_bias for token %d must be between -100 and 100, got %v", token, bias)
+		}
+	}
+	if p.checkLogitBias && len(p.LogitBias) > 0 {
+		size, err := VocabSize(p.Model)
+		if err != nil {
+			return err
+		}
+		for token := range p.LogitBias {
+			if token < 0 || token >= size {
+				return fmt.Errorf("gpt3: logit_bias token %d is outside the vocabulary of %s (0 to %d)", token, p.Model, size-1)
+			}
+		}
+	}
+	return nil
+}
+
+// setStream marks p as a streamed request.
+func (p *Params) setStream() {
+	p.Stream = true
+	if p.includeUsage {
+		p.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+}
+
+// withTimeout returns ctx bounded by the Timeout option, if it was given.
+func (p *Params) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.timeout > 0 {
+		return context.WithTimeout(ctx, p.timeout)
+	}
+	return ctx, func() {}
+}
+
+// completionBudget returns the number of tokens the completion may use at
+// most, for rate limiting purposes. defaultMaxTokens is used when
+// max_tokens is unset.
+func (p *Params) completionBudget(defaultMaxTokens int) int {
+	n := 1
+	if p.N != nil && *p.N > 1 {
+		n = *p.N
+	}
+	if p.BestOf != nil && *p.BestOf > n {
+		n = *p.BestOf
+	}
+	maxTokens := defaultMaxTokens
+	if p.MaxTokens != nil {
+		maxTokens = *p.MaxTokens
+	}
+	return n * maxTokens
+}
+
+// checkRange returns an error if v is set and lies outside [min, max].
+func checkRange(name string, v *float64, min, max float64) error {
+	if v != nil && (*v < min || *v > max) {
+		return fmt.Errorf("gpt3: %s must be between %v and %v, got %v", name, min, max, *v)
+	}
+	return nil
+}
+
+// bestOfWarnThreshold is the best_of value above which a warning is logged.
+const bestOfWarnThreshold = 5
+
+// bestOfTokensWarnThreshold is the number of tokens best_of may generate
+// server-side above which a warning is logged.
+const bestOfTokensWarnThreshold = 8192
+
+// maxLogprobs is the largest number of top log probabilities per token
+// that the API returns.
+const maxLogprobs = 5
+
+// maxStopSequences is the number of stop sequences accepted by the API.
+const maxStopSequences = 4
+
+// StopSequences is a list of sequences where the API stops generating
+// further tokens. A single sequence is encoded as a bare JSON string and
+// several as a JSON array.
+type StopSequences []string
+
+// MarshalJSON implements json.Marshaler.
+func (s StopSequences) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0])
+	}
+	return json.Marshal([]string(s))
+}
+
+// An Option sets a parameter on the request payload sent to the API.
+type Option func(*Params)
+
+// Model sets the ID of the model used to generate the completion, such as
+// DefaultModel. It overrides the client's default model, set with
+// WithModel; one of the two is required.
+func Model(model string) Option {
+	return func(r *Params) { r.Model = model }
+}
+
+// MaxTokens sets the maximum number of tokens to generate.
+func MaxTokens(n int) Option {
+	return func(r *Params) { r.MaxTokens = Int(n) }
+}
+
+// Temperature sets the sampling temperature. Higher values make the output
+// more random, lower values make it more focused and deterministic.
+func Temperature(t float64) Option {
+	return func(r *Params) { r.Temperature = Float64(t) }
+}
+
+// TopP sets the nucleus sampling probability mass.
+func TopP(p float64) Option {
+	return func(r *Params) { r.TopP = Float64(p) }
+}
+
+// N sets how many completions to generate for each prompt. It must be at
+// least 1; without this option the API generates one. When streaming, the
+// chunks of all choices arrive interleaved and are told apart by their
+// Index.
+func N(n int) Option {
+	return func(r *Params) { r.N = Int(n) }
+}
+
+// Stop sets up to four sequences where the API will stop generating further
+// tokens. Passing more than four makes the request fail.
+func Stop(sequences ...string) Option {
+	return func(r *Params) { r.Stop = sequences }
+}
+
+// PresencePenalty penalizes tokens that already appear in the text so far,
+// encouraging the model to talk about new topics. It must be between -2.0
+// and 2.0.
+func PresencePenalty(p float64) Option {
+	return func(r *Params) { r.PresencePenalty = Float64(p) }
+}
+
+// FrequencyPenalty penalizes tokens in proportion to how often they already
+// appear in the text so far, reducing verbatim repetition. It must be
+// between -2.0 and 2.0.
+func FrequencyPenalty(f float64) Option {
+	return func(r *Params) { r.FrequencyPenalty = Float64(f) }
+}
+
+// LogitBias adjusts the likelihood of specific tokens appearing in the
+// completion. The map is keyed by token ID and each bias must be between
+// -100 (ban the token) and 100 (force it). Token IDs are specific to the
+// tokenizer of the model in use, so they must be looked up for that model;
+// for example, -100 on 50256 suppresses <|endoftext|> in GPT-2 era models.
+func LogitBias(bias map[int]float64) Option {
+	return func(r *Params) { r.LogitBias = bias }
+}
+
+// CheckLogitBias makes request validation reject LogitBias token IDs that
+// lie outside the vocabulary of the model's encoding, which the API
+// otherwise ignores without an error. It requires the model's encoding to
+// be known to EncodingForModel, so it is opt-in.
+func CheckLogitBias() Option {
+	return func(r *Params) { r.checkLogitBias = true }
+}
+
+// User sets a stable identifier for the end user on whose behalf the request
+// is made, which helps OpenAI monitor and detect abuse. Avoid sending
+// personal information; a hashed account ID works well.
+func User(id string) Option {
+	return func(r *Params) { r.User = id }
+}
+
+// Suffix sets the text that comes after the completion, so the model fills
+// in the text between the prompt and the suffix. This is useful for code
+// insertion.
+func Suffix(s string) Option {
+	return func(r *Params) { r.Suffix = s }
+}
+
+// Echo makes the API include the prompt in the returned text, along with
+// its log probabilities when those are requested.
+func Echo(echo bool) Option {
+	return func(r *Params) { r.Echo = echo }
+}
+
+// BestOf generates n completions server-side and returns the one with the
+// highest log probability per token. It must be greater than or equal to
+// the value of N. Every candidate is billed, so large values consume quota
+// quickly.
+func BestOf(n int) Option {
+	return func(r *Params) { r.BestOf = Int(n) }
+}
+
+// Logprobs requests the log probabilities of the n most likely tokens at
+// each position, in addition to the chosen token. n must be between 0 and
+// 5; zero returns only the log probabilities of the chosen tokens. The
+// results are available in Choice.Logprobs.
+func Logprobs(n int) Option {
+	return func(r *Params) { r.Logprobs = Int(n) }
+}
+
+// Seed makes sampling deterministic on a best-effort basis: repeated
+// requests with the same seed and parameters should return the same result.
+// Compare the SystemFingerprint of responses to detect backend changes that
+// affect determinism.
+func Seed(seed int) Option {
+	return func(r *Params) { r.Seed = Int(seed) }
+}
+
+// ResponseFormat sets the format the model must produce, such as "text" or
+// "json_object".
+func ResponseFormat(kind string) Option {
+	return func(r *Params) { r.ResponseFormat = &ResponseFormatParam{Type: kind} }
+}
+
+// ResponseFormatJSON enables JSON mode, which constrains the model to emit a
+// valid JSON object that can be passed straight to json.Unmarshal. The
+// prompt or messages must still instruct the model to produce JSON, or the
+// request may be rejected or generate whitespace until it hits the token
+// limit.
+func ResponseFormatJSON() Option {
+	return ResponseFormat("json_object")
+}
+
+// StreamIncludeUsage makes a streamed request report its token usage. The
+// API then sends a final chunk with no choices whose Usage holds the totals
+// for the whole request. Without this option streamed responses carry no
+// usage at all. It has no effect on requests that are not streamed.
+func StreamIncludeUsage() Option {
+	return func(r *Params) { r.includeUsage = true }
+}
+
+// StreamIdleTimeout aborts a streamed request with ErrStreamIdle if no
+// data arrives from the server for d, guarding against streams that stall
+// without closing. It is off by default, is independent of any deadline on
+// the context, and has no effect on requests that are not streamed.
+func StreamIdleTimeout(d time.Duration) Option {
+	return func(r *Params) { r.idleTimeout = d }
+}
+
+// Tools sets the tools the model may call in a chat completion.
+func Tools(tools ...Tool) Option {
+	return func(r *Params) { r.Tools = tools }
+}
+
+// ToolChoice controls whether the model calls tools: "none", "auto" or
+// "required". Use ToolChoiceFunction to force a particular function.
+func ToolChoice(choice string) Option {
+	return func(r *Params) { r.ToolChoice = choice }
+}
+
+// ToolChoiceFunction forces the model to call the named function.
+func ToolChoiceFunction(name string) Option {
+	return func(r *Params) {
+		r.ToolChoice = Tool{Type: "function", Function: FunctionDef{Name: name}}
+	}
+}
+
+// Timeout limits how long a single call may take, including retries and,
+// for streamed calls, the whole stream. It is applied on top of the context
+// passed to the call, so the earlier of the two deadlines wins, and is
+// convenient for callers that do not otherwise use a context.
+func Timeout(d time.Duration) Option {
+	return func(r *Params) { r.timeout = d }
+}
+
+// WithExtraBody adds fields to the JSON request body, so parameters this
+// package does not know about yet can be used as soon as the API supports
+// them. Fields set by other options take precedence over fields of the same
+// name given here. The fields are sent as is, without any validation.
+func WithExtraBody(fields map[string]interface{}) Option {
+	return func(r *Params) {
+		if r.extra == nil {
+			r.extra = make(map[string]interface{}, len(fields))
+		}
+		for k, v := range fields {
+			r.extra[k] = v
+		}
+	}
+}
+
+// withExtra returns body with the fields of extra it does not set itself
+// added. body must encode to a JSON object.
+func withExtra(body interface{}, extra map[string]interface{}) (interface{}, error) {
+	if len(extra) == 0 {
+		return body, nil
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		if _, ok := fields[k]; ok {
+			continue
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		fields[k] = value
+	}
+	return fields, nil
+}
+
+// WithRequestAPIKey authenticates a single request with key instead of the
+// client's APIKey. The client itself is not modified, so one client can
+// serve many tenants.
+func WithRequestAPIKey(key string) Option {
+	return func(r *Params) { r.apiKey = key }
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header of a single request,
+// which is sent unchanged with every retry so that the server can avoid
+// acting on, and billing for, the same request twice. Without this option
+// a random key is used whenever retries are enabled.
+func WithIdempotencyKey(key string) Option {
+	return WithHeader(idempotencyKeyHeader, key)
+}
+
+// WithHeader sets an HTTP header on a single request, replacing any value
+// the client would otherwise send. A User-Agent is prepended to the
+// client's own rather than replacing it.
+func WithHeader(name, value string) Option {
+	return func(r *Params) {
+		if r.header == nil {
+			r.header = make(http.Header)
+		}
+		r.header.Set(name, value)
+	}
+}
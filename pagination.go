@@ -0,0 +1,66 @@
+package gpt3
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// pageSize is the number of items requested per page by the ListAll
+// methods, the maximum the list endpoints accept.
+const pageSize = 100
+
+// page is a single page of a cursor-paginated list endpoint.
+type page[T any] struct {
+	Data    []T  `json:"data"`
+	HasMore bool `json:"has_more"`
+}
+
+// listAll fetches every page of the list endpoint at path, following the
+// after cursor, which id extracts from the last item of each page. ctx is
+// checked between pages.
+func listAll[T any](ctx context.Context, c *Client, path string, id func(T) string) ([]T, error) {
+	var all []T
+	after := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		q := url.Values{"limit": {strconv.Itoa(pageSize)}}
+		if after != "" {
+			q.Set("after", after)
+		}
+		req, err := c.NewRequest("GET", path+"?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		p := new(page[T])
+		if _, err := c.Do(ctx, req, p); err != nil {
+			return nil, err
+		}
+		all = append(all, p.Data...)
+		if !p.HasMore || len(p.Data) == 0 {
+			return all, nil
+		}
+		after = id(p.Data[len(p.Data)-1])
+	}
+}
+
+// ListAll returns every file of the organization, fetching as many pages
+// as needed.
+func (s *FilesService) ListAll(ctx context.Context) ([]*File, error) {
+	return listAll(ctx, s.client, "files", func(f *File) string { return f.ID })
+}
+
+// ListAll returns every fine-tuning job of the organization, fetching as
+// many pages as needed.
+func (s *FineTuningService) ListAll(ctx context.Context) ([]*FineTuneJob, error) {
+	return listAll(ctx, s.client, "fine_tuning/jobs", func(j *FineTuneJob) string { return j.ID })
+}
+
+// ListAll returns every batch of the organization, fetching as many pages
+// as needed.
+func (s *BatchesService) ListAll(ctx context.Context) ([]*Batch, error) {
+	return listAll(ctx, s.client, "batches", func(b *Batch) string { return b.ID })
+}
@@ -0,0 +1,48 @@
+package gpt3
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitInfo holds the rate limit state reported in the headers of an
+// API response. Values missing from the response are left zero.
+type RateLimitInfo struct {
+	LimitRequests     int           // x-ratelimit-limit-requests
+	LimitTokens       int           // x-ratelimit-limit-tokens
+	RemainingRequests int           // x-ratelimit-remaining-requests
+	RemainingTokens   int           // x-ratelimit-remaining-tokens
+	ResetRequests     time.Duration // x-ratelimit-reset-requests
+	ResetTokens       time.Duration // x-ratelimit-reset-tokens
+}
+
+// ParseRateLimit extracts the rate limit headers of resp. Missing or
+// malformed headers yield zero values.
+func ParseRateLimit(resp *http.Response) RateLimitInfo {
+	if resp == nil {
+		return RateLimitInfo{}
+	}
+	h := resp.Header
+	return RateLimitInfo{
+		LimitRequests:     headerInt(h, "x-ratelimit-limit-requests"),
+		LimitTokens:       headerInt(h, "x-ratelimit-limit-tokens"),
+		RemainingRequests: headerInt(h, "x-ratelimit-remaining-requests"),
+		RemainingTokens:   headerInt(h, "x-ratelimit-remaining-tokens"),
+		ResetRequests:     headerDuration(h, "x-ratelimit-reset-requests"),
+		ResetTokens:       headerDuration(h, "x-ratelimit-reset-tokens"),
+	}
+}
+
+// headerInt returns the integer value of header key, or zero.
+func headerInt(h http.Header, key string) int {
+	n, _ := strconv.Atoi(h.Get(key))
+	return n
+}
+
+// headerDuration returns the value of header key, such as "6m0s", as a
+// duration, or zero.
+func headerDuration(h http.Header, key string) time.Duration {
+	d, _ := time.ParseDuration(h.Get(key))
+	return d
+}
@@ -0,0 +1,157 @@
+package gpt3
+
+import (
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff     = 30 * time.Second
+)
+
+// idempotencyKeyHeader is the header identifying the attempts of one
+// logical request.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// newIdempotencyKey returns a random (version 4) UUID.
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// shouldRetry reports whether a request that produced resp and err is worth
+// sending again as retry number attempt. Successful attempts and requests
+// whose context is done are never retried; otherwise the client's
+// ShouldRetry decides, defaulting to DefaultShouldRetry.
+func (c *Client) shouldRetry(ctx context.Context, resp *http.Response, err error, attempt int) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if c.ShouldRetry != nil {
+		return c.ShouldRetry(resp, err, attempt)
+	}
+	return DefaultShouldRetry(resp, err, attempt)
+}
+
+// DefaultShouldRetry is the retry policy used when Client.ShouldRetry is
+// nil. Transport errors, throttling (429) and server errors (500, 502, 503
+// and 504) are retried; other client errors are not. Custom policies can
+// call it to extend rather than replace these rules.
+func DefaultShouldRetry(resp *http.Response, err error, attempt int) bool {
+	if resp == nil {
+		return err != nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// now returns the current time according to the client's clock.
+func (c *Client) now() time.Time {
+	if c.nowFunc != nil {
+		return c.nowFunc()
+	}
+	return time.Now()
+}
+
+// sleep waits for d according to the client's clock, or until ctx is done,
+// in which case it returns ctx.Err(). A clock set with WithClock cannot be
+// interrupted, so ctx is only checked once it returns.
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	if c.sleepFunc != nil {
+		c.sleepFunc(d)
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryDelay returns how long to wait before retry number attempt+1. The
+// delay the server asks for on resp wins; otherwise the delay grows
+// exponentially from RetryBackoff with random jitter.
+func (c *Client) retryDelay(attempt int, resp *http.Response) time.Duration {
+	if d, ok := c.retryAfter(resp); ok {
+		return d
+	}
+
+	backoff := c.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	for i := 0; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+
+	// Wait between half and all of the computed backoff so that clients
+	// failing together do not retry in lockstep.
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter returns the delay the server asks for before the next attempt.
+// The retry-after-ms header sent by OpenAI is preferred for its precision,
+// then the standard Retry-After header in seconds or as an HTTP date.
+// Missing or malformed values report false.
+func (c *Client) retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	if ms, err := strconv.ParseFloat(resp.Header.Get("retry-after-ms"), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	v := resp.Header.Get("Retry-After")
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(c.now())
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// rewindBody resets the body of req so it can be sent again.
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.GetBody == nil {
+		return errors.New("gpt3: request body cannot be rewound for retry")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
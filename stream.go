@@ -0,0 +1,214 @@
+package gpt3
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxEventSize is the largest server-sent event the stream reader accepts.
+const maxEventSize = 1 << 20
+
+// streamDone is the payload of the event that terminates a stream.
+var streamDone = []byte("[DONE]")
+
+// ErrStreamIdle is returned by streaming calls aborted because no data
+// arrived within the time set by the StreamIdleTimeout option.
+var ErrStreamIdle = errors.New("gpt3: stream idle timeout exceeded")
+
+// streamReader decodes the server-sent events of a streaming response.
+type streamReader struct {
+	ctx     context.Context
+	resp    *http.Response
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	idle    *idleTimer
+}
+
+// stream sends req and returns a reader over the events of the response.
+// If idleTimeout is positive, the stream is aborted with ErrStreamIdle when
+// no data arrives for that long, including while waiting for the response.
+// The caller must close the returned reader.
+func (c *Client) stream(ctx context.Context, req *http.Request, idleTimeout time.Duration) (*streamReader, error) {
+	idle := newIdleTimer(ctx, idleTimeout)
+	ctx = idle.ctx
+
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.BareDo(ctx, req)
+	if err != nil {
+		idle.stop()
+		return nil, idle.err(err)
+	}
+
+	recordResponse(ctx, resp)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxEventSize)
+	return &streamReader{ctx: ctx, resp: resp, body: resp.Body, scanner: scanner, idle: idle}, nil
+}
+
+// idleTimer cancels its context when it is not reset in time. A zero
+// timeout disables it.
+type idleTimer struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+	timer   *time.Timer
+	fired   int32
+}
+
+func newIdleTimer(ctx context.Context, timeout time.Duration) *idleTimer {
+	t := &idleTimer{timeout: timeout}
+	t.ctx, t.cancel = context.WithCancel(ctx)
+	if timeout > 0 {
+		t.timer = time.AfterFunc(timeout, func() {
+			atomic.StoreInt32(&t.fired, 1)
+			t.cancel()
+		})
+	}
+	return t
+}
+
+// reset restarts the timer after data has arrived.
+func (t *idleTimer) reset() {
+	if t.timer != nil {
+		t.timer.Reset(t.timeout)
+	}
+}
+
+// pause stops the timer until the next reset.
+func (t *idleTimer) pause() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// stop releases the timer and its context.
+func (t *idleTimer) stop() {
+	t.pause()
+	t.cancel()
+}
+
+// err returns ErrStreamIdle in place of err if the timer fired.
+func (t *idleTimer) err(err error) error {
+	if atomic.LoadInt32(&t.fired) == 1 {
+		return ErrStreamIdle
+	}
+	return err
+}
+
+// next decodes the data of the next event into v. It returns io.EOF once
+// the server sends the [DONE] sentinel or closes the stream.
+//
+// An event may span several lines: its data lines are joined with newlines
+// and it ends at the first blank line, as the server-sent events format
+// prescribes. Comment lines, such as the ": keep-alive" lines some servers
+// send to hold a connection open, and fields other than event and data are
+// skipped.
+//
+// An error reported by the server in the middle of the stream, either as an
+// event of type error or as data holding an error envelope, is returned as
+// an *APIError.
+func (s *streamReader) next(v interface{}) error {
+	event, data, err := s.nextEvent()
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bytes.TrimSpace(data), streamDone) {
+		return io.EOF
+	}
+	if event == "error" || bytes.HasPrefix(bytes.TrimSpace(data), []byte(`{"error"`)) {
+		return s.apiError(data)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// apiError decodes an error sent in the stream. The payload is either an
+// {"error": {...}} envelope or the error object itself.
+func (s *streamReader) apiError(data []byte) error {
+	apiError := &APIError{
+		Response:   s.resp,
+		StatusCode: s.resp.StatusCode,
+		RequestID:  s.resp.Header.Get("x-request-id"),
+	}
+	envelope := struct {
+		Error *APIError `json:"error"`
+	}{Error: apiError}
+	if json.Unmarshal(data, &envelope) != nil || apiError.Message == "" {
+		if json.Unmarshal(data, apiError) != nil || apiError.Message == "" {
+			apiError.Message = strings.TrimSpace(string(data))
+		}
+	}
+	return apiError
+}
+
+// nextEvent returns the type and data of the next event that has data. The
+// idle timer only runs while it waits, so time spent by the caller handling
+// an event does not count against it.
+func (s *streamReader) nextEvent() (event string, data []byte, err error) {
+	s.idle.reset()
+	defer s.idle.pause()
+
+	hasData := false
+	for s.scanner.Scan() {
+		s.idle.reset()
+		line := s.scanner.Bytes()
+		if len(line) == 0 {
+			if hasData {
+				return event, data, nil
+			}
+			event = ""
+			continue
+		}
+		if line[0] == ':' {
+			continue
+		}
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			value = bytes.TrimPrefix(value, []byte(" "))
+		}
+		switch string(field) {
+		case "event":
+			event = string(value)
+			continue
+		case "data":
+		default:
+			continue
+		}
+		if hasData {
+			data = append(data, '\n')
+		}
+		data = append(data, value...)
+		hasData = true
+	}
+
+	err = s.scanner.Err()
+	if err == nil {
+		// A stream cut off without a final blank line still delivers its
+		// last event.
+		if hasData {
+			return event, data, nil
+		}
+		return "", nil, io.EOF
+	}
+	// Reads from a body whose request was canceled fail with a transport
+	// error; the context's error is more useful.
+	if s.ctx.Err() != nil {
+		return "", nil, s.idle.err(s.ctx.Err())
+	}
+	return "", nil, err
+}
+
+// Close closes the underlying response body.
+func (s *streamReader) Close() error {
+	s.idle.stop()
+	return s.body.Close()
+}
@@ -0,0 +1,64 @@
+package gpt3
+
+import (
+	"context"
+	"strings"
+	"text/template"
+)
+
+// A Template is a prompt with placeholders, written in the syntax of
+// text/template, such as "Translate {{.Text}} into {{.Language}}.".
+type Template struct {
+	tmpl *template.Template
+}
+
+// A TemplateOption configures a Template.
+type TemplateOption func(*template.Template)
+
+// StrictTemplate makes rendering fail when the data lacks a key used by
+// the template, so that misspelled placeholders are reported instead of
+// rendering as "<no value>".
+func StrictTemplate() TemplateOption {
+	return func(t *template.Template) { t.Option("missingkey=error") }
+}
+
+// NewTemplate parses text as a prompt template.
+func NewTemplate(text string, options ...TemplateOption) (*Template, error) {
+	t := template.New("prompt")
+	for _, option := range options {
+		option(t)
+	}
+	if _, err := t.Parse(text); err != nil {
+		return nil, err
+	}
+	return &Template{tmpl: t}, nil
+}
+
+// MustTemplate is like NewTemplate but panics if text cannot be parsed. It
+// simplifies the initialization of package-level templates.
+func MustTemplate(text string, options ...TemplateOption) *Template {
+	t, err := NewTemplate(text, options...)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render returns the prompt with its placeholders filled in from data.
+func (t *Template) Render(data interface{}) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// CompleteTemplate renders tmpl with data and creates a completion for the
+// resulting prompt, as CompleteContext does.
+func (s *CompletionsService) CompleteTemplate(ctx context.Context, tmpl *Template, data interface{}, options ...Option) (*Completions, error) {
+	prompt, err := tmpl.Render(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.CompleteContext(ctx, prompt, options...)
+}
@@ -0,0 +1,268 @@
+package gpt3
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Encodings used by OpenAI models.
+const (
+	EncodingCL100kBase = "cl100k_base"
+	EncodingO200kBase  = "o200k_base"
+	EncodingP50kBase   = "p50k_base"
+)
+
+// modelEncodings maps model name prefixes to the tokenizer encoding they
+// use. Longer prefixes are listed before shorter ones they extend.
+var modelEncodings = []struct {
+	prefix   string
+	encoding string
+}{
+	{"gpt-4o", EncodingO200kBase},
+	{"o1", EncodingO200kBase},
+	{"o3", EncodingO200kBase},
+	{"gpt-4", EncodingCL100kBase},
+	{"gpt-3.5-turbo", EncodingCL100kBase},
+	{"text-embedding-3", EncodingCL100kBase},
+	{"text-embedding-ada-002", EncodingCL100kBase},
+	{"davinci-002", EncodingCL100kBase},
+	{"babbage-002", EncodingCL100kBase},
+	{"text-davinci", EncodingP50kBase},
+	{"code-davinci", EncodingP50kBase},
+}
+
+// EncodingForModel returns the name of the tokenizer encoding used by
+// model.
+func EncodingForModel(model string) (string, error) {
+	for _, e := range modelEncodings {
+		if strings.HasPrefix(model, e.prefix) {
+			return e.encoding, nil
+		}
+	}
+	return "", fmt.Errorf("gpt3: unknown encoding for model %q", model)
+}
+
+// CountTokens estimates the number of tokens text occupies for model.
+//
+// The count is an approximation: text is split the way the cl100k_base
+// pre-tokenizer splits it, into words, numbers of up to three digits,
+// punctuation and whitespace, and each piece is charged an estimated
+// number of BPE tokens. It is usually close for English prose but may be
+// off by 20% or more for code, unusual formatting and non-Latin scripts,
+// so leave headroom when checking against a context window. An error is
+// returned for models whose encoding is unknown.
+func CountTokens(model, text string) (int, error) {
+	if _, err := EncodingForModel(model); err != nil {
+		return 0, err
+	}
+	return estimateTokens(text), nil
+}
+
+// estimateTokens returns the approximate number of tokens in text.
+func estimateTokens(text string) int {
+	n := 0
+	for len(text) > 0 {
+		var piece string
+		piece, text = nextPiece(text)
+		n += pieceTokens(piece)
+	}
+	return n
+}
+
+// nextPiece splits the first pre-token off s. A single leading space is
+// kept with the word or punctuation that follows it, as the tokenizer does.
+func nextPiece(s string) (piece, rest string) {
+	start := 0
+	if s[0] == ' ' && len(s) > 1 {
+		if r, _ := utf8.DecodeRuneInString(s[1:]); !unicode.IsSpace(r) && !unicode.IsDigit(r) {
+			start = 1
+		}
+	}
+
+	r, size := utf8.DecodeRuneInString(s[start:])
+	end := start + size
+	switch {
+	case unicode.IsLetter(r):
+		end = scan(s, end, unicode.IsLetter, -1)
+	case unicode.IsDigit(r):
+		end = scan(s, end, unicode.IsDigit, 3)
+	case unicode.IsSpace(r):
+		end = scan(s, end, unicode.IsSpace, -1)
+	default:
+		end = scan(s, end, isPunct, -1)
+	}
+	return s[:end], s[end:]
+}
+
+// scan advances from i over runes satisfying f, stopping after max runes
+// in total when max is positive.
+func scan(s string, i int, f func(rune) bool, max int) int {
+	count := 1
+	for i < len(s) && (max <= 0 || count < max) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !f(r) {
+			break
+		}
+		i += size
+		count++
+	}
+	return i
+}
+
+// isPunct reports whether r is neither a letter, a digit nor whitespace.
+func isPunct(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+}
+
+// pieceTokens estimates the number of BPE tokens in a single pre-token.
+func pieceTokens(piece string) int {
+	word := strings.TrimPrefix(piece, " ")
+	if word == "" {
+		return 1
+	}
+	r, _ := utf8.DecodeRuneInString(word)
+	switch {
+	case unicode.IsSpace(r):
+		// Runs of whitespace, such as indentation, are mostly single
+		// tokens.
+		return 1
+	case unicode.IsDigit(r):
+		return 1
+	case unicode.IsLetter(r):
+		if utf8.RuneCountInString(word) != len(word) {
+			// Non-ASCII text is split into far smaller pieces; roughly
+			// one token per three bytes of UTF-8.
+			return (len(word) + 2) / 3
+		}
+		// Common English words are single tokens; longer words split
+		// into chunks of about eight characters.
+		return 1 + (len(word)-1)/8
+	default:
+		return (utf8.RuneCountInString(word) + 1) / 2
+	}
+}
+
+// ContextWindows maps model name prefixes to the size of the model's
+// context window in tokens. The longest matching prefix wins. Entries may
+// be added or changed to cover new models.
+var ContextWindows = map[string]int{
+	"gpt-3.5-turbo-instruct": 4096,
+	"gpt-3.5-turbo":          16385,
+	"gpt-4":                  8192,
+	"gpt-4-32k":              32768,
+	"gpt-4-turbo":            128000,
+	"gpt-4o":                 128000,
+	"davinci-002":            16384,
+	"babbage-002":            16384,
+}
+
+// ContextWindow returns the context window size of model, looked up in
+// ContextWindows.
+func ContextWindow(model string) (int, error) {
+	best := ""
+	for prefix := range ContextWindows {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return 0, fmt.Errorf("gpt3: unknown context window for model %q", model)
+	}
+	return ContextWindows[best], nil
+}
+
+// vocabSizes maps encodings to the number of tokens in their vocabulary,
+// special tokens included.
+var vocabSizes = map[string]int{
+	EncodingCL100kBase: 100277,
+	EncodingO200kBase:  200019,
+	EncodingP50kBase:   50281,
+}
+
+// VocabSize returns the number of tokens in the vocabulary of model's
+// encoding. Valid token IDs run from 0 to VocabSize-1.
+func VocabSize(model string) (int, error) {
+	encoding, err := EncodingForModel(model)
+	if err != nil {
+		return 0, err
+	}
+	return vocabSizes[encoding], nil
+}
+
+// A TruncateStrategy selects which part of a prompt TruncateToFit removes.
+type TruncateStrategy int
+
+const (
+	// TruncateHead removes text from the start of the prompt.
+	TruncateHead TruncateStrategy = iota
+
+	// TruncateTail removes text from the end of the prompt.
+	TruncateTail
+
+	// TruncateMiddle keeps the start and end of the prompt and removes
+	// text from the middle.
+	TruncateMiddle
+)
+
+// TruncateToFit shortens prompt so that its tokens plus reserveTokens, the
+// budget kept for the completion, fit in the context window of model. The
+// prompt is returned unchanged if it already fits. Token counts are the
+// estimates of CountTokens, so reserve some headroom.
+func TruncateToFit(model, prompt string, reserveTokens int, strategy TruncateStrategy) (string, error) {
+	window, err := ContextWindow(model)
+	if err != nil {
+		return "", err
+	}
+	if _, err := EncodingForModel(model); err != nil {
+		return "", err
+	}
+	budget := window - reserveTokens
+	if budget <= 0 {
+		return "", fmt.Errorf("gpt3: reserving %d tokens leaves no room for the prompt in the %d token window of %s", reserveTokens, window, model)
+	}
+
+	var pieces []string
+	var counts []int
+	total := 0
+	for rest := prompt; len(rest) > 0; {
+		var piece string
+		piece, rest = nextPiece(rest)
+		pieces = append(pieces, piece)
+		counts = append(counts, pieceTokens(piece))
+		total += counts[len(counts)-1]
+	}
+	if total <= budget {
+		return prompt, nil
+	}
+
+	switch strategy {
+	case TruncateHead:
+		return strings.Join(pieces[len(pieces)-fitPieces(counts, budget, true):], ""), nil
+	case TruncateTail:
+		return strings.Join(pieces[:fitPieces(counts, budget, false)], ""), nil
+	case TruncateMiddle:
+		head := fitPieces(counts, budget/2, false)
+		tail := fitPieces(counts, budget-budget/2, true)
+		return strings.Join(pieces[:head], "") + strings.Join(pieces[len(pieces)-tail:], ""), nil
+	}
+	return "", fmt.Errorf("gpt3: unknown truncate strategy %d", strategy)
+}
+
+// fitPieces returns how many pieces, taken from the start of counts or from
+// the end if fromEnd is set, fit in budget tokens.
+func fitPieces(counts []int, budget int, fromEnd bool) int {
+	used := 0
+	for i := range counts {
+		c := counts[i]
+		if fromEnd {
+			c = counts[len(counts)-1-i]
+		}
+		if used+c > budget {
+			return i
+		}
+		used += c
+	}
+	return len(counts)
+}
@@ -0,0 +1,149 @@
+package gpt3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// A Tracer starts spans around API calls. It mirrors the part of the
+// OpenTelemetry trace.Tracer API the client needs, so the package does not
+// depend on OpenTelemetry. An adapter is a few lines:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, gpt3.Span) {
+//		ctx, span := t.Tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+//
+// where otelSpan maps SetAttribute to span.SetAttributes and RecordError to
+// span.RecordError followed by span.SetStatus(codes.Error, err.Error()).
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// A Span is a single traced operation started by a Tracer.
+type Span interface {
+	// SetAttribute records a key-value attribute on the span. Values are
+	// strings, ints or int64s.
+	SetAttribute(key string, value interface{})
+
+	// RecordError records err on the span and marks it as failed.
+	RecordError(err error)
+
+	// End completes the span.
+	End()
+}
+
+// Metrics receives the outcome of every completion and chat call, so that
+// it can be recorded in a metrics system such as Prometheus without the
+// package depending on one.
+type Metrics interface {
+	// ObserveRequest is called once per call, whether it succeeded or
+	// not. status is the HTTP status code, or zero if no response was
+	// received. usage is zero for failed calls and for streams that do not
+	// report it; see StreamIncludeUsage.
+	ObserveRequest(model string, status int, latency time.Duration, usage Usage)
+}
+
+// apiCall tracks a single completion or chat call for observability.
+type apiCall struct {
+	client *Client
+	model  string
+	start  time.Time
+	span   Span
+
+	// retries counts the attempts BareDo repeated for the call.
+	retries int
+
+	// finishReason is the finish reason of the first choice, if known.
+	finishReason string
+
+	// resp is the response of a streamed call, whose body is still being
+	// read when the call ends.
+	resp *http.Response
+}
+
+// apiCallKey is the context key under which startCall stores the call.
+type apiCallKey struct{}
+
+// countRetry records a retry on the call tracked by ctx, if any.
+func countRetry(ctx context.Context) {
+	if call, ok := ctx.Value(apiCallKey{}).(*apiCall); ok {
+		call.retries++
+	}
+}
+
+// recordResponse records resp as the response of the streamed call tracked
+// by ctx, if any.
+func recordResponse(ctx context.Context, resp *http.Response) {
+	if call, ok := ctx.Value(apiCallKey{}).(*apiCall); ok {
+		call.resp = resp
+	}
+}
+
+// startCall begins tracking a call named name to model. The returned
+// context carries the span, if any, and must be used for the call.
+func (c *Client) startCall(ctx context.Context, name, model string) (context.Context, *apiCall) {
+	call := &apiCall{client: c, model: model, start: c.now()}
+	if c.Tracer != nil {
+		ctx, call.span = c.Tracer.Start(ctx, name)
+		call.span.SetAttribute("gen_ai.request.model", model)
+	}
+	return context.WithValue(ctx, apiCallKey{}, call), call
+}
+
+// end finishes tracking the call, given its response, the usage it
+// reported, if known, and its error. A nil resp stands for the response
+// recorded by recordResponse, if any.
+func (a *apiCall) end(resp *http.Response, usage *Usage, err error) {
+	if resp == nil {
+		resp = a.resp
+	}
+	latency := a.client.now().Sub(a.start)
+	status := statusCode(resp, err)
+	var u Usage
+	if usage != nil && err == nil {
+		u = *usage
+	}
+
+	if a.client.Metrics != nil {
+		a.client.Metrics.ObserveRequest(a.model, status, latency, u)
+	}
+	if a.client.LogUsage && err == nil {
+		a.client.logf("gpt3: usage model=%s prompt_tokens=%d completion_tokens=%d total_tokens=%d latency_ms=%d finish_reason=%s",
+			a.model, u.PromptTokens, u.CompletionTokens, u.TotalTokens, latency.Milliseconds(), a.finishReason)
+	}
+
+	if a.span == nil {
+		return
+	}
+	a.span.SetAttribute("gpt3.latency_ms", latency.Milliseconds())
+	a.span.SetAttribute("gpt3.retries", a.retries)
+	if status != 0 {
+		a.span.SetAttribute("http.response.status_code", status)
+	}
+	if usage != nil && err == nil {
+		a.span.SetAttribute("gen_ai.usage.input_tokens", u.PromptTokens)
+		a.span.SetAttribute("gen_ai.usage.output_tokens", u.CompletionTokens)
+	}
+	if err != nil {
+		a.span.RecordError(err)
+	}
+	a.span.End()
+}
+
+// statusCode returns the HTTP status code of a call, taken from its
+// response or from an *APIError, or zero if the request was never answered.
+func statusCode(resp *http.Response, err error) int {
+	if resp != nil {
+		return resp.StatusCode
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package gpt3
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Connection pool settings of the transport returned by NewTransport.
+const (
+	// DefaultMaxIdleConns is the number of idle connections kept open
+	// across all hosts.
+	DefaultMaxIdleConns = 100
+
+	// DefaultMaxIdleConnsPerHost is the number of idle connections kept
+	// open to the API host. net/http keeps only 2, which causes
+	// connection churn when many requests run concurrently.
+	DefaultMaxIdleConnsPerHost = 100
+
+	// DefaultIdleConnTimeout is how long an idle connection is kept open.
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
+// NewTransport returns the transport clients use by default: a copy of
+// http.DefaultTransport, honouring proxy settings from the environment,
+// with a connection pool sized by DefaultMaxIdleConns,
+// DefaultMaxIdleConnsPerHost and DefaultIdleConnTimeout. If the program has
+// replaced http.DefaultTransport with another RoundTripper, a transport
+// with the standard library's defaults is built instead. Callers can adjust
+// the result and install it with WithTransport.
+func NewTransport() *http.Transport {
+	var t *http.Transport
+	if dt, ok := http.DefaultTransport.(*http.Transport); ok {
+		t = dt.Clone()
+	} else {
+		t = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+	}
+	t.MaxIdleConns = DefaultMaxIdleConns
+	t.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	t.IdleConnTimeout = DefaultIdleConnTimeout
+	return t
+}